@@ -0,0 +1,46 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"reflect"
+)
+
+// BloomFilter is a probabilistic set membership test, as implemented by
+// *bloom.BloomFilter from github.com/bits-and-blooms/bloom. Test must
+// never return false for a member of the set, but may return true for
+// values that are not members.
+type BloomFilter interface {
+	Test(data []byte) bool
+}
+
+// BloomEnum returns a Checker that accepts a string value that is a
+// member of a large allow-list, and returns it unprocessed. The filter
+// is consulted first, so that most values outside the allow-list are
+// rejected cheaply. As a bloom filter may report false positives, every
+// value the filter accepts is then confirmed with exact, which must
+// report the true membership of the value. BloomEnum panics if exact
+// is nil.
+func BloomEnum(filter BloomFilter, exact func(string) bool) Checker {
+	if exact == nil {
+		panic("BloomEnum requires an exact membership check")
+	}
+	return bloomEnumC{filter, exact}
+}
+
+type bloomEnumC struct {
+	filter BloomFilter
+	exact  func(string) bool
+}
+
+func (c bloomEnumC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	if c.filter.Test([]byte(s)) && c.exact(s) {
+		return s, nil
+	}
+	return nil, CoerceError{Expected: "allowed value", Got: v, Path: path}
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type bloomSuite struct{}
+
+var _ = gc.Suite(&bloomSuite{})
+
+// firstByteFilter is a deliberately poor bloom filter that only looks
+// at the first byte of its input, so it has plenty of false positives.
+type firstByteFilter map[byte]bool
+
+func (f firstByteFilter) Test(data []byte) bool {
+	return len(data) > 0 && f[data[0]]
+}
+
+func (s *bloomSuite) TestBloomEnum(c *gc.C) {
+	allowed := map[string]bool{"sku-1": true, "sku-2": true}
+	var exactCalls int
+	exact := func(s string) bool {
+		exactCalls++
+		return allowed[s]
+	}
+	sch := schema.BloomEnum(firstByteFilter{'s': true}, exact)
+
+	out, err := sch.Coerce("sku-1", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "sku-1")
+	c.Assert(exactCalls, gc.Equals, 1)
+
+	// A false positive from the filter is caught by the exact check.
+	out, err = sch.Coerce("sku-3", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected allowed value, got string\("sku-3"\)`)
+	c.Assert(exactCalls, gc.Equals, 2)
+
+	// A negative from the filter never reaches the exact check.
+	out, err = sch.Coerce("other", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected allowed value, got string\("other"\)`)
+	c.Assert(exactCalls, gc.Equals, 2)
+
+	out, err = sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected string, got int\(42\)`)
+
+	c.Assert(func() { schema.BloomEnum(firstByteFilter{}, nil) }, gc.PanicMatches, "BloomEnum requires an exact membership check")
+}
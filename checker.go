@@ -4,7 +4,11 @@
 package schema
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // The Coerce method of the Checker interface is called recursively when
@@ -42,28 +46,292 @@ type oneOfC struct {
 }
 
 func (c oneOfC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c oneOfC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
 	for _, o := range c.options {
-		newv, err := o.Coerce(v, path)
+		// Only the option that succeeds contributes to st.
+		b := st.branch()
+		newv, err := coerceField(o, v, path, b)
 		if err == nil {
+			st.merge(b)
 			return newv, nil
 		}
 	}
-	return nil, error_{"", v, path}
+	return nil, CoerceError{Got: v, Path: path}
 }
 
-// pathAsPrefix returns a string consisting of the path elements
-// suitable for using as the prefix of an error message. If path
-// starts with a ".", the dot is omitted.
-func pathAsPrefix(path []string) string {
-	if len(path) == 0 {
-		return ""
+// AllOf returns a Checker that coerces the value with each of the
+// provided checkers in turn, from left to right, passing the value
+// returned by each checker as input to the next one, and returns the
+// value returned by the last one. The value is thus required to satisfy
+// all the checkers, and later checkers see the result of the coercions
+// done by earlier ones. If a checker fails, AllOf returns its error
+// unchanged. AllOf with no checkers returns the value unprocessed.
+func AllOf(checkers ...Checker) Checker {
+	return allOfC{checkers}
+}
+
+type allOfC struct {
+	checkers []Checker
+}
+
+func (c allOfC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c allOfC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	for _, checker := range c.checkers {
+		newv, err := coerceField(checker, v, path, st)
+		if err != nil {
+			return nil, err
+		}
+		v = newv
+	}
+	return v, nil
+}
+
+// Not returns a Checker that succeeds, returning the value unprocessed,
+// exactly when c fails to coerce it, as for a field that must not be
+// the string "default", which Not(Const("default")) expresses. Even when
+// c transforms values, the value returned is always the original one.
+func Not(c Checker) Checker {
+	return notC{c}
+}
+
+type notC struct {
+	checker Checker
+}
+
+func (c notC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if _, err := c.checker.Coerce(v, path); err == nil {
+		return nil, errorf(path, "value must not match constraint")
+	}
+	return v, nil
+}
+
+// ByType returns a Checker that coerces the value with the checker
+// associated with its kind in mapping, such as a string checker for
+// reflect.String and a FieldMap for reflect.Map, which suits fields
+// that accept several forms of value. Unlike OneOf, a single checker is
+// ever tried, so its error is reported as is. A nil value has the kind
+// reflect.Invalid. If no checker is associated with the kind of the
+// value, the error lists the kinds handled.
+func ByType(mapping map[reflect.Kind]Checker) Checker {
+	kinds := make([]string, 0, len(mapping))
+	for kind := range mapping {
+		if kind == reflect.Invalid {
+			kinds = append(kinds, "nil")
+		} else {
+			kinds = append(kinds, kind.String())
+		}
 	}
-	var s string
-	if path[0] == "." {
-		s = strings.Join(path[1:], "")
-	} else {
-		s = strings.Join(path, "")
+	sort.Strings(kinds)
+	return byTypeC{mapping, strings.Join(kinds, " or ")}
+}
+
+type byTypeC struct {
+	mapping map[reflect.Kind]Checker
+	want    string
+}
+
+func (c byTypeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c byTypeC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	checker, ok := c.mapping[reflect.ValueOf(v).Kind()]
+	if !ok {
+		return nil, CoerceError{Expected: c.want, Got: v, Path: path}
 	}
+	return coerceField(checker, v, path, st)
+}
+
+// OrSentinel returns a Checker that returns result, unprocessed, when
+// the value is a string equal to sentinel, and otherwise processes the
+// value with inner. It models fields such as "a number of seconds, or
+// never", where the sentinel result is typically a value inner could
+// not produce, such as -1 or nil, so that callers can tell the two
+// apart. The type of the coerced value is therefore that of result for
+// the sentinel, and whatever inner returns otherwise.
+func OrSentinel(inner Checker, sentinel string, result interface{}) Checker {
+	return orSentinelC{inner, sentinel, result}
+}
+
+type orSentinelC struct {
+	inner    Checker
+	sentinel string
+	result   interface{}
+}
+
+func (c orSentinelC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c orSentinelC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	if v != nil && reflect.TypeOf(v).Kind() == reflect.String && reflect.ValueOf(v).String() == c.sentinel {
+		return c.result, nil
+	}
+	return coerceField(c.inner, v, path, st)
+}
+
+// WithDefault returns a Checker that processes the value with c, or
+// processes dflt with c in its place when the value is nil. This allows
+// defaults outside of a FieldMap, such as for list elements or top
+// level values. WithDefault panics if dflt is rejected by c, so that a
+// misconfigured default is caught early.
+func WithDefault(c Checker, dflt interface{}) Checker {
+	if _, err := c.Coerce(dflt, nil); err != nil {
+		panic(fmt.Sprintf("WithDefault got an invalid default: %v", err))
+	}
+	return withDefaultC{c, dflt}
+}
+
+type withDefaultC struct {
+	checker Checker
+	dflt    interface{}
+}
+
+func (c withDefaultC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c withDefaultC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	if v == nil {
+		v = c.dflt
+	}
+	return coerceField(c.checker, v, path, st)
+}
+
+// OrDefault returns a Checker that processes the value with c, or
+// processes dflt with c in its place when c rejects the value, so that
+// a malformed optional value falls back to its default rather than
+// failing the whole coercion. An error processing dflt is returned as
+// is. OrDefault panics if dflt is rejected by c, as does WithDefault.
+func OrDefault(c Checker, dflt interface{}) Checker {
+	if _, err := c.Coerce(dflt, nil); err != nil {
+		panic(fmt.Sprintf("OrDefault got an invalid default: %v", err))
+	}
+	return orDefaultC{c, dflt}
+}
+
+type orDefaultC struct {
+	checker Checker
+	dflt    interface{}
+}
+
+func (c orDefaultC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c orDefaultC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	// Only the attempt that succeeds contributes to st.
+	b := st.branch()
+	if out, err := coerceField(c.checker, v, path, b); err == nil {
+		st.merge(b)
+		return out, nil
+	}
+	return coerceField(c.checker, c.dflt, path, st)
+}
+
+// Transform returns a Checker that processes the value with c and
+// then converts the coerced value with f, returning its result, as for
+// wrapping a validated string in a domain type. An error returned by f
+// is reported along with the path of the value.
+func Transform(c Checker, f func(interface{}) (interface{}, error)) Checker {
+	return transformC{c, f}
+}
+
+type transformC struct {
+	checker Checker
+	f       func(interface{}) (interface{}, error)
+}
+
+func (c transformC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c transformC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	out, err := coerceField(c.checker, v, path, st)
+	if err != nil {
+		return nil, err
+	}
+	out, err = c.f(out)
+	if err != nil {
+		return nil, errorf(path, "%v", err)
+	}
+	return out, nil
+}
+
+// Lazy returns a Checker that processes the value with the checker
+// returned by f, which is only called on first use. This allows
+// recursive schemas, as a checker can be referred to before it is
+// constructed, such as for tree nodes whose children have the same
+// schema as the node:
+//
+//	var node schema.Checker
+//	node = schema.FieldMap(schema.Fields{
+//		"name":     schema.String(),
+//		"children": schema.List(schema.Lazy(func() schema.Checker { return node })),
+//	}, schema.Defaults{"children": schema.Omit})
+//
+// As the depth of the value is then only bounded by the input, values
+// nested more than 1000 levels deep are rejected, which also catches
+// input that refers to itself.
+func Lazy(f func() Checker) Checker {
+	return &lazyC{f: f}
+}
+
+const lazyMaxDepth = 1000
+
+type lazyC struct {
+	once    sync.Once
+	f       func() Checker
+	checker Checker
+}
+
+func (c *lazyC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c *lazyC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	if pathDepth(path) > lazyMaxDepth {
+		return nil, errorf(path, "value nested more than %d levels deep", lazyMaxDepth)
+	}
+	c.once.Do(func() {
+		c.checker = c.f()
+	})
+	return coerceField(c.checker, v, path, st)
+}
+
+// pathDepth returns the number of map keys and list indexes in path.
+func pathDepth(path []string) int {
+	depth := 0
+	for _, elem := range path {
+		if elem == "." || elem == "[" {
+			depth++
+		}
+	}
+	return depth
+}
+
+// pathAsString renders path as a string. Checkers build paths by
+// appending ".", key for map values and "[", index, "]" for list
+// elements, so that nested values render as in "servers[2].port". A
+// leading "." is omitted.
+func pathAsString(path []string) string {
+	if len(path) > 0 && path[0] == "." {
+		path = path[1:]
+	}
+	return strings.Join(path, "")
+}
+
+// pathAsPrefix returns a string consisting of the path elements
+// suitable for using as the prefix of an error message, as rendered
+// by pathAsString.
+func pathAsPrefix(path []string) string {
+	s := pathAsString(path)
 	if s == "" {
 		return ""
 	}
@@ -0,0 +1,97 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// CurrencyCode returns a Checker that accepts a three letter ISO 4217
+// currency code in any case, such as "usd", and returns it in upper
+// case. Codes not in the package's built-in table of ISO 4217 codes
+// are rejected.
+func CurrencyCode() Checker {
+	return codeC{"currency code", currencyCodes}
+}
+
+// CountryCode returns a Checker that accepts a two letter ISO 3166-1
+// alpha-2 country code in any case, such as "gb", and returns it in
+// upper case. Codes not in the package's built-in table of ISO 3166-1
+// codes are rejected.
+func CountryCode() Checker {
+	return codeC{"country code", countryCodes}
+}
+
+type codeC struct {
+	what  string
+	codes map[string]bool
+}
+
+func (c codeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	code := strings.ToUpper(s)
+	if !c.codes[code] {
+		return nil, errorf(path, "%q is not a recognized %s", s, c.what)
+	}
+	return code, nil
+}
+
+func codeSet(codes string) map[string]bool {
+	m := make(map[string]bool)
+	for _, code := range strings.Fields(codes) {
+		m[code] = true
+	}
+	return m
+}
+
+// currencyCodes holds the ISO 4217 currency codes, including the
+// codes for funds, precious metals and testing.
+var currencyCodes = codeSet(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF
+	BMD BND BOB BOV BRL BSD BTN BWP BYN BZD CAD CDF CHE CHF CHW CLF
+	CLP CNY COP COU CRC CUC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB
+	EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR
+	ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD
+	KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR
+	MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK
+	PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP
+	SLE SLL SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD
+	TWD TZS UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV WST XAF
+	XAG XAU XBA XBB XBC XBD XCD XCG XDR XOF XPD XPF XPT XSU XTS XUA
+	XXX YER ZAR ZMW ZWG ZWL
+`)
+
+// countryCodes holds the officially assigned ISO 3166-1 alpha-2
+// country codes.
+var countryCodes = codeSet(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ
+	BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+	CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ
+	DE DJ DK DM DO DZ
+	EC EE EG EH ER ES ET
+	FI FJ FK FM FO FR
+	GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY
+	HK HM HN HR HT HU
+	ID IE IL IM IN IO IQ IR IS IT
+	JE JM JO JP
+	KE KG KH KI KM KN KP KR KW KY KZ
+	LA LB LC LI LK LR LS LT LU LV LY
+	MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ
+	NA NC NE NF NG NI NL NO NP NR NU NZ
+	OM
+	PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+	QA
+	RE RO RS RU RW
+	SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ
+	TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ
+	UA UG UM US UY UZ
+	VA VC VE VG VI VN VU
+	WF WS
+	YE YT
+	ZA ZM ZW
+`)
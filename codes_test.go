@@ -0,0 +1,49 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"fmt"
+	"strings"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type codesSuite struct{}
+
+var _ = gc.Suite(&codesSuite{})
+
+func (s *codesSuite) TestCurrencyCode(c *gc.C) {
+	sch := schema.CurrencyCode()
+	for _, in := range []string{"USD", "usd", "Eur", "jpy", "XAU"} {
+		out, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, strings.ToUpper(in))
+	}
+
+	for _, in := range []string{"Dollars", "US", "ABC", ""} {
+		_, err := sch.Coerce(in, aPath)
+		c.Check(err, gc.ErrorMatches, fmt.Sprintf(`<path>: %q is not a recognized currency code`, in))
+	}
+	_, err := sch.Coerce(840, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(840\)`)
+	_, err = sch.Coerce(nil, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got nothing`)
+}
+
+func (s *codesSuite) TestCountryCode(c *gc.C) {
+	sch := schema.CountryCode()
+	for _, in := range []string{"GB", "us", "De", "ax"} {
+		out, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, strings.ToUpper(in))
+	}
+
+	for _, in := range []string{"UK", "GBR", "XX", ""} {
+		_, err := sch.Coerce(in, aPath)
+		c.Check(err, gc.ErrorMatches, fmt.Sprintf(`<path>: %q is not a recognized country code`, in))
+	}
+}
@@ -22,7 +22,7 @@ func (c constC) Coerce(v interface{}, path []string) (interface{}, error) {
 	if reflect.DeepEqual(v, c.value) {
 		return v, nil
 	}
-	return nil, error_{fmt.Sprintf("%#v", c.value), v, path}
+	return nil, CoerceError{Expected: fmt.Sprintf("%#v", c.value), Got: v, Path: path}
 }
 
 // Nil returns a Checker that only succeeds if the input is nil. To tweak the
@@ -53,5 +53,68 @@ func (c nilC) Coerce(v interface{}, path []string) (interface{}, error) {
 		return v, nil
 	}
 	label := fmt.Sprintf("empty %s", c.valueLabel)
-	return nil, error_{label, v, path}
+	return nil, CoerceError{Expected: label, Got: v, Path: path}
+}
+
+// Nullable returns a Checker that returns nil when the input is nil,
+// as for an explicit null in a JSON or YAML document, and otherwise
+// processes the input with c. A nil pointer is taken as nil too.
+//
+// As FieldMap passes nil for missing fields, use Nullable along with a
+// default for the field to tell a missing key, which gets the default,
+// from a key that is present but null, which gets nil. With a
+// schema.Omit default, a null key is thus present in the coerced map
+// with a nil value, and a missing key is absent from it, so that both
+// encode back to JSON as they were decoded.
+func Nullable(c Checker) Checker {
+	return nullableC{c}
+}
+
+type nullableC struct {
+	checker Checker
+}
+
+func (c nullableC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c nullableC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	return coerceField(c.checker, v, path, st)
+}
+
+// Forbidden returns a Checker that fails whenever a value is present,
+// with an error explaining that the field is no longer supported for
+// the given reason. It is meant to be used in FieldMap fields for
+// deprecated or removed settings, so that users get a helpful message
+// rather than having the value silently ignored.
+//
+// Within a FieldMap, the field is rejected whenever its key is present
+// in the input, even with a nil value. Use a schema.Omit default to keep
+// the field out of the coerced map when it is absent. Used on its own,
+// the Checker cannot tell a missing value from a nil one, and accepts
+// nil.
+func Forbidden(reason string) Checker {
+	return forbiddenC{reason}
+}
+
+type forbiddenC struct {
+	reason string
+}
+
+func (c forbiddenC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return nil, c.reject(path)
+}
+
+// reject returns the error reported for a value present at path.
+func (c forbiddenC) reject(path []string) error {
+	return errorf(path, "field is no longer supported: %s", c.reason)
 }
@@ -0,0 +1,318 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"reflect"
+	"sort"
+)
+
+// Constraint is implemented by cross-field constraints checked by a
+// Constrained checker once a map has been coerced.
+type Constraint interface {
+	// Check returns an error if the constraint doesn't hold for the
+	// coerced map m, found at path.
+	Check(m map[string]interface{}, path []string) error
+}
+
+// Constrained returns a Checker that coerces a value with c, which must
+// result in a map[string]interface{} as returned by FieldMap, and then
+// checks that all the constraints hold for the coerced map, in order.
+// This allows expressing rules that involve several fields, which no
+// field checker can enforce on its own.
+func Constrained(c Checker, constraints ...Constraint) Checker {
+	return constrainedC{c, constraints}
+}
+
+type constrainedC struct {
+	checker     Checker
+	constraints []Constraint
+}
+
+func (c constrainedC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c constrainedC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	out, err := coerceField(c.checker, v, path, st)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := out.(map[string]interface{})
+	if !ok {
+		return nil, CoerceError{Expected: "map[string]", Got: out, Path: path}
+	}
+	failed := false
+	for _, constraint := range c.constraints {
+		if _, ok := constraint.(Dependency); ok && st != nil {
+			st.stats.Dependencies++
+		}
+		if err := constraint.Check(m, path); err != nil {
+			if err := st.fail(err); err != nil {
+				return nil, err
+			}
+			failed = true
+		}
+	}
+	if failed {
+		return nil, errAccumulated
+	}
+	return m, nil
+}
+
+// RefersToKeysOf returns a Constraint requiring the value of field to
+// be one of the keys of the map held in mapField, as for a defaultPool
+// field that must name one of the entries under pools. The coerced
+// value must equal a coerced key, so a key coerced to an int doesn't
+// match the string "1". The constraint holds if field is absent from
+// the coerced map.
+func RefersToKeysOf(field, mapField string) Constraint {
+	return refersToKeysOf{field, mapField}
+}
+
+type refersToKeysOf struct {
+	field    string
+	mapField string
+}
+
+func (r refersToKeysOf) Check(m map[string]interface{}, path []string) error {
+	ref, ok := m[r.field]
+	if !ok {
+		return nil
+	}
+	var keys []string
+	if rv := reflect.ValueOf(m[r.mapField]); rv.Kind() == reflect.Map {
+		for _, k := range rv.MapKeys() {
+			if reflect.DeepEqual(k.Interface(), ref) {
+				return nil
+			}
+			keys = append(keys, fmt.Sprint(k.Interface()))
+		}
+	}
+	sort.Strings(keys)
+	return errorf(append(path[:len(path):len(path)], ".", r.field), "%#v is not a key of %s, expected one of %q", ref, r.mapField, keys)
+}
+
+// Canonicalized returns a Checker that coerces a value with c, which
+// must result in a map[string]interface{} as returned by FieldMap, and
+// then passes the coerced map to canonicalize, returning its result.
+// It is meant as the final stage of processing, producing a stable
+// representation of equivalent inputs, for instance by sorting lists
+// whose order doesn't matter, lowercasing names or filling in derived
+// fields. It therefore runs before any serialization of the result, as
+// done by Fingerprint. An error returned by canonicalize is reported
+// along with the path of the map.
+//
+// The canonicalize function should be idempotent, so that coercing an
+// already canonical value leaves it unchanged.
+func Canonicalized(c Checker, canonicalize func(map[string]interface{}) (map[string]interface{}, error)) Checker {
+	return canonicalizedC{c, canonicalize}
+}
+
+type canonicalizedC struct {
+	checker      Checker
+	canonicalize func(map[string]interface{}) (map[string]interface{}, error)
+}
+
+func (c canonicalizedC) Coerce(v interface{}, path []string) (interface{}, error) {
+	out, err := c.checker.Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := out.(map[string]interface{})
+	if !ok {
+		return nil, CoerceError{Expected: "map[string]", Got: out, Path: path}
+	}
+	m, err = c.canonicalize(m)
+	if err != nil {
+		return nil, errorf(path, "%v", err)
+	}
+	return m, nil
+}
+
+// RelOp holds a relational operator for a FieldRelation.
+type RelOp string
+
+const (
+	LessThan       RelOp = "<"
+	LessOrEqual    RelOp = "<="
+	GreaterThan    RelOp = ">"
+	GreaterOrEqual RelOp = ">="
+)
+
+// FieldRelation is a Constraint requiring the numeric values of two
+// fields to be in the relation given by Op, as in
+// FieldRelation{"maxConnections", GreaterOrEqual, "minConnections"}.
+// The constraint holds if either field is absent from the coerced map.
+type FieldRelation struct {
+	A  string
+	Op RelOp
+	B  string
+}
+
+// Check implements Constraint.
+func (r FieldRelation) Check(m map[string]interface{}, path []string) error {
+	a, aok := m[r.A]
+	b, bok := m[r.B]
+	if !aok || !bok {
+		return nil
+	}
+	apath := append(path[:len(path):len(path)], ".", r.A)
+	af, err := Float().Coerce(a, apath)
+	if err != nil {
+		return err
+	}
+	bf, err := Float().Coerce(b, append(path[:len(path):len(path)], ".", r.B))
+	if err != nil {
+		return err
+	}
+	x, y := af.(float64), bf.(float64)
+	var ok bool
+	switch r.Op {
+	case LessThan:
+		ok = x < y
+	case LessOrEqual:
+		ok = x <= y
+	case GreaterThan:
+		ok = x > y
+	case GreaterOrEqual:
+		ok = x >= y
+	default:
+		return errorf(path, "unknown relational operator %q", r.Op)
+	}
+	if !ok {
+		return errorf(apath, "%v must be %s %s (%v)", a, r.Op, r.B, b)
+	}
+	return nil
+}
+
+// ChecksumMatch is a Constraint requiring the checksum held in
+// ChecksumField to be the checksum of the content held in ContentField,
+// computed with the hash algorithm named by Algo, one of "sha1",
+// "sha256" or "sha512". The content must be a string or a []byte. The
+// checksum must be a string holding either the hex encoding of the
+// digest, in either case, or its base64 encoding, in the standard or
+// URL alphabet and with or without padding. The constraint holds if
+// either field is absent from the coerced map.
+type ChecksumMatch struct {
+	ContentField  string
+	ChecksumField string
+	Algo          string
+}
+
+var checksumAlgos = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Check implements Constraint.
+func (r ChecksumMatch) Check(m map[string]interface{}, path []string) error {
+	content, cok := m[r.ContentField]
+	checksum, sok := m[r.ChecksumField]
+	if !cok || !sok {
+		return nil
+	}
+	newHash, ok := checksumAlgos[r.Algo]
+	if !ok {
+		return errorf(path, "unknown checksum algorithm %q", r.Algo)
+	}
+	h := newHash()
+	switch content := content.(type) {
+	case string:
+		h.Write([]byte(content))
+	case []byte:
+		h.Write(content)
+	default:
+		return CoerceError{Expected: "string or []byte", Got: content, Path: append(path[:len(path):len(path)], ".", r.ContentField)}
+	}
+	spath := append(path[:len(path):len(path)], ".", r.ChecksumField)
+	s, ok := checksum.(string)
+	if !ok {
+		return CoerceError{Expected: "string", Got: checksum, Path: spath}
+	}
+	want, ok := decodeChecksum(s, h.Size())
+	if !ok {
+		return errorf(spath, "invalid %s checksum %q", r.Algo, s)
+	}
+	if !bytes.Equal(h.Sum(nil), want) {
+		return errorf(spath, "%s checksum does not match %s", r.Algo, r.ContentField)
+	}
+	return nil
+}
+
+// decodeChecksum decodes a digest of the given size from its hex or
+// base64 encoding, reporting whether it succeeded.
+func decodeChecksum(s string, size int) ([]byte, bool) {
+	if len(s) == hex.EncodedLen(size) {
+		if b, err := hex.DecodeString(s); err == nil {
+			return b, true
+		}
+	}
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding,
+		base64.URLEncoding,
+		base64.RawStdEncoding,
+		base64.RawURLEncoding,
+	} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) == size {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// Dependency is a Constraint requiring Field to be given when the
+// field On has one of the listed values, as in
+// Dependency{Field: "cipher", On: "mode", Values: []interface{}{"tls", "mtls"}}.
+// Value holds a single triggering value, and is used when Values is
+// empty; values are compared with reflect.DeepEqual against the
+// coerced value of On. If OnPresence is set, Field is required
+// whenever On is present, regardless of its value. In all cases, Field
+// is allowed with any value of On, and isn't required when On is
+// absent from the coerced map.
+type Dependency struct {
+	Field      string
+	On         string
+	Value      interface{}
+	Values     []interface{}
+	OnPresence bool
+}
+
+// Check implements Constraint.
+func (d Dependency) Check(m map[string]interface{}, path []string) error {
+	if _, ok := m[d.Field]; ok {
+		return nil
+	}
+	on, ok := m[d.On]
+	if !ok {
+		return nil
+	}
+	fpath := append(path[:len(path):len(path)], ".", d.Field)
+	if d.OnPresence {
+		return errorf(fpath, "required when %q is specified", d.On)
+	}
+	values := d.Values
+	if len(values) == 0 {
+		values = []interface{}{d.Value}
+	}
+	for _, value := range values {
+		if !reflect.DeepEqual(on, value) {
+			continue
+		}
+		if len(values) == 1 {
+			return errorf(fpath, "required when %q is %v", d.On, value)
+		}
+		return errorf(fpath, "required when %q has one of %v", d.On, values)
+	}
+	return nil
+}
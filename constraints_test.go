@@ -0,0 +1,303 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type constraintsSuite struct{}
+
+var _ = gc.Suite(&constraintsSuite{})
+
+func (s *constraintsSuite) TestRefersToKeysOf(c *gc.C) {
+	sch := schema.Constrained(schema.FieldMap(schema.Fields{
+		"pools":       schema.StringMap(schema.Any()),
+		"defaultPool": schema.String(),
+	}, schema.Defaults{
+		"defaultPool": schema.Omit,
+	}), schema.RefersToKeysOf("defaultPool", "pools"))
+
+	v := map[string]interface{}{
+		"pools":       map[string]interface{}{"fast": 1, "slow": 2},
+		"defaultPool": "fast",
+	}
+	out, err := sch.Coerce(v, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, v)
+
+	_, err = sch.Coerce(map[string]interface{}{
+		"pools": map[string]interface{}{"fast": 1},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+
+	_, err = sch.Coerce(map[string]interface{}{
+		"pools":       map[string]interface{}{"fast": 1, "slow": 2},
+		"defaultPool": "medium",
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.defaultPool: "medium" is not a key of pools, expected one of \["fast" "slow"\]`)
+
+	_, err = sch.Coerce(map[string]interface{}{
+		"pools":       map[string]interface{}{},
+		"defaultPool": "fast",
+	}, nil)
+	c.Check(err, gc.ErrorMatches, `defaultPool: "fast" is not a key of pools, expected one of \[\]`)
+
+	// Errors from the checker are reported before any constraint.
+	_, err = sch.Coerce(map[string]interface{}{"pools": 1}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.pools: expected map, got int\(1\)`)
+
+	// Coerced values are compared as they are, not as strings.
+	sch = schema.Constrained(schema.FieldMap(schema.Fields{
+		"pools":       schema.Map(schema.Int(), schema.Any()),
+		"defaultPool": schema.Any(),
+	}, nil), schema.RefersToKeysOf("defaultPool", "pools"))
+	_, err = sch.Coerce(map[string]interface{}{
+		"pools":       map[interface{}]interface{}{1: "a"},
+		"defaultPool": int64(1),
+	}, aPath)
+	c.Check(err, gc.IsNil)
+	_, err = sch.Coerce(map[string]interface{}{
+		"pools":       map[interface{}]interface{}{1: "a"},
+		"defaultPool": "1",
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.defaultPool: "1" is not a key of pools, expected one of \["1"\]`)
+}
+
+func (s *constraintsSuite) TestConstrainedNonMap(c *gc.C) {
+	sch := schema.Constrained(schema.String(), schema.RefersToKeysOf("a", "b"))
+	_, err := sch.Coerce("x", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected map\[string\], got string\("x"\)`)
+}
+
+func canonicalizeApp(m map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	var tags []string
+	for _, tag := range m["tags"].([]interface{}) {
+		tags = append(tags, strings.ToLower(tag.(string)))
+	}
+	if len(tags) > 3 {
+		return nil, fmt.Errorf("too many tags")
+	}
+	sort.Strings(tags)
+	sorted := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		sorted[i] = tag
+	}
+	out["tags"] = sorted
+	out["id"] = m["name"].(string) + "-" + strconv.Itoa(len(tags))
+	return out, nil
+}
+
+func (s *constraintsSuite) TestCanonicalized(c *gc.C) {
+	sch := schema.Canonicalized(schema.FieldMap(schema.Fields{
+		"name": schema.String(),
+		"tags": schema.List(schema.String()),
+		"id":   schema.String(),
+	}, schema.Defaults{
+		"id": schema.Omit,
+	}), canonicalizeApp)
+
+	out, err := sch.Coerce(map[string]interface{}{
+		"name": "app",
+		"tags": []interface{}{"Web", "api"},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	expect := map[string]interface{}{
+		"name": "app",
+		"tags": []interface{}{"api", "web"},
+		"id":   "app-2",
+	}
+	c.Check(out, gc.DeepEquals, expect)
+
+	// Canonicalization is idempotent.
+	again, err := sch.Coerce(out, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(again, gc.DeepEquals, expect)
+
+	// Equivalent inputs have the same fingerprint.
+	a, err := schema.Fingerprint(sch, map[string]interface{}{
+		"name": "app",
+		"tags": []interface{}{"API", "web"},
+	})
+	c.Assert(err, gc.IsNil)
+	b, err := schema.Fingerprint(sch, out)
+	c.Assert(err, gc.IsNil)
+	c.Check(string(a), gc.Equals, string(b))
+
+	_, err = sch.Coerce(map[string]interface{}{
+		"name": "app",
+		"tags": []interface{}{"a", "b", "c", "d"},
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: too many tags`)
+}
+
+func (s *constraintsSuite) TestFieldRelation(c *gc.C) {
+	sch := schema.Constrained(schema.FieldMap(schema.Fields{
+		"minConnections": schema.Int(),
+		"maxConnections": schema.Int(),
+		"ratio":          schema.Float(),
+		"name":           schema.String(),
+	}, schema.Defaults{
+		"minConnections": schema.Omit,
+		"ratio":          schema.Omit,
+		"name":           schema.Omit,
+	}),
+		schema.FieldRelation{"maxConnections", schema.GreaterOrEqual, "minConnections"},
+		schema.FieldRelation{"ratio", schema.LessThan, "maxConnections"},
+	)
+
+	_, err := sch.Coerce(map[string]interface{}{"minConnections": 5, "maxConnections": 5}, aPath)
+	c.Assert(err, gc.IsNil)
+
+	// Relations with missing fields hold.
+	_, err = sch.Coerce(map[string]interface{}{"maxConnections": 1}, aPath)
+	c.Assert(err, gc.IsNil)
+
+	_, err = sch.Coerce(map[string]interface{}{"minConnections": 10, "maxConnections": 5}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.maxConnections: 5 must be >= minConnections \(10\)`)
+
+	_, err = sch.Coerce(map[string]interface{}{"maxConnections": 5, "ratio": 5.0}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.ratio: 5 must be < maxConnections \(5\)`)
+
+	sch = schema.Constrained(schema.FieldMap(schema.Fields{
+		"a": schema.Int(),
+		"b": schema.String(),
+	}, nil), schema.FieldRelation{"a", schema.LessOrEqual, "b"})
+	_, err = sch.Coerce(map[string]interface{}{"a": 1, "b": "x"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.b: expected float, got string\("x"\)`)
+
+	sch = schema.Constrained(schema.FieldMap(schema.Fields{
+		"a": schema.Int(),
+		"b": schema.Int(),
+	}, nil), schema.FieldRelation{"a", "==", "b"})
+	_, err = sch.Coerce(map[string]interface{}{"a": 1, "b": 1}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: unknown relational operator "=="`)
+}
+
+func (s *constraintsSuite) TestChecksumMatch(c *gc.C) {
+	sch := schema.Constrained(schema.FieldMap(schema.Fields{
+		"content": schema.String(),
+		"sha256":  schema.String(),
+	}, schema.Defaults{
+		"sha256": schema.Omit,
+	}), schema.ChecksumMatch{"content", "sha256", "sha256"})
+
+	for _, sum := range []string{
+		"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824",
+		"LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=",
+		"LPJNul-wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ",
+	} {
+		c.Logf("checksum %q", sum)
+		_, err := sch.Coerce(map[string]interface{}{"content": "hello", "sha256": sum}, aPath)
+		c.Check(err, gc.IsNil)
+	}
+
+	// The constraint holds without a checksum.
+	_, err := sch.Coerce(map[string]interface{}{"content": "hello"}, aPath)
+	c.Assert(err, gc.IsNil)
+
+	_, err = sch.Coerce(map[string]interface{}{
+		"content": "hellO",
+		"sha256":  "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.sha256: sha256 checksum does not match content`)
+
+	_, err = sch.Coerce(map[string]interface{}{"content": "hello", "sha256": "2cf24dba"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.sha256: invalid sha256 checksum "2cf24dba"`)
+
+	sch = schema.Constrained(schema.FieldMap(schema.Fields{
+		"content": schema.Any(),
+		"sum":     schema.String(),
+	}, nil), schema.ChecksumMatch{"content", "sum", "sha1"})
+	_, err = sch.Coerce(map[string]interface{}{
+		"content": []byte("hello"),
+		"sum":     "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+	}, aPath)
+	c.Check(err, gc.IsNil)
+	_, err = sch.Coerce(map[string]interface{}{"content": 1, "sum": ""}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.content: expected string or \[\]byte, got int\(1\)`)
+
+	sch = schema.Constrained(schema.FieldMap(schema.Fields{
+		"content": schema.String(),
+		"sum":     schema.String(),
+	}, nil), schema.ChecksumMatch{"content", "sum", "md5"})
+	_, err = sch.Coerce(map[string]interface{}{"content": "", "sum": ""}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: unknown checksum algorithm "md5"`)
+}
+
+func (s *constraintsSuite) TestDependency(c *gc.C) {
+	fields := schema.FieldMap(schema.Fields{
+		"mode":   schema.String(),
+		"cipher": schema.String(),
+		"port":   schema.Int(),
+	}, schema.Defaults{
+		"mode":   schema.Omit,
+		"cipher": schema.Omit,
+		"port":   schema.Omit,
+	})
+	sch := schema.Constrained(fields,
+		schema.Dependency{Field: "cipher", On: "mode", Values: []interface{}{"a", "b"}},
+		schema.Dependency{Field: "port", On: "mode", Value: "b"},
+	)
+
+	for _, m := range []map[string]interface{}{
+		{},
+		{"mode": "x"},
+		{"cipher": "aes"},
+		{"mode": "x", "cipher": "aes"},
+		{"mode": "a", "cipher": "aes"},
+		{"mode": "b", "cipher": "aes", "port": 1},
+	} {
+		_, err := sch.Coerce(m, aPath)
+		c.Check(err, gc.IsNil)
+	}
+
+	_, err := sch.Coerce(map[string]interface{}{"mode": "a"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.cipher: required when "mode" has one of \[a b\]`)
+	_, err = sch.Coerce(map[string]interface{}{"mode": "b", "cipher": "aes"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.port: required when "mode" is b`)
+	_, err = sch.Coerce(map[string]interface{}{"mode": "b", "port": 1}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.cipher: required when "mode" has one of \[a b\]`)
+
+	// Values are compared with the coerced value.
+	sch = schema.Constrained(fields, schema.Dependency{Field: "cipher", On: "port", Value: int64(443)})
+	_, err = sch.Coerce(map[string]interface{}{"port": "443"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.cipher: required when "port" is 443`)
+	_, err = sch.Coerce(map[string]interface{}{"port": "80"}, aPath)
+	c.Check(err, gc.IsNil)
+}
+
+func (s *constraintsSuite) TestDependencyOnPresence(c *gc.C) {
+	sch := schema.Constrained(schema.FieldMap(schema.Fields{
+		"cert": schema.String(),
+		"key":  schema.String(),
+	}, schema.Defaults{
+		"cert": schema.Omit,
+		"key":  schema.Omit,
+	}), schema.Dependency{Field: "cert", On: "key", OnPresence: true})
+
+	for _, m := range []map[string]interface{}{
+		{},
+		{"cert": "c"},
+		{"cert": "c", "key": "k"},
+	} {
+		_, err := sch.Coerce(m, aPath)
+		c.Check(err, gc.IsNil)
+	}
+
+	_, err := sch.Coerce(map[string]interface{}{"key": "k"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.cert: required when "key" is specified`)
+}
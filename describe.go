@@ -0,0 +1,147 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Describer is implemented by checkers that can describe the values
+// they accept, for use by Describe. Checkers defined outside this
+// package should implement it to be described accurately.
+type Describer interface {
+	// Describe returns a short description of the accepted values,
+	// such as "list of int".
+	Describe() string
+}
+
+// Describe returns a human-readable description of the values accepted
+// by c, for instance to generate reference documentation for a
+// configuration schema that is guaranteed to match the code.
+//
+// For a FieldMap, every field is described on its own line, sorted by
+// name, with its type, whether it is required or has a default, and the
+// feature it depends on, if any, as in:
+//
+//	name: string (required)
+//	port: int (default 8080)
+//	db: map (required)
+//	  host: string (required)
+//
+// The fields of nested FieldMaps are indented beneath the field holding
+// them, including those of FieldMaps used as list or map elements.
+// Checkers of other packages are described by their Describe method if
+// they implement Describer, and by their type name otherwise.
+func Describe(c Checker) string {
+	label, fmap := describeChecker(c)
+	if fmap == nil {
+		return label
+	}
+	var buf strings.Builder
+	describeFields(&buf, *fmap, "")
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func describeFields(buf *strings.Builder, c fieldMapC, indent string) {
+	names := make([]string, 0, len(c.fields))
+	for name := range c.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		label, fmap := describeChecker(c.fields[name])
+		var notes []string
+		if dflt, ok := c.defaults[name]; ok {
+			if dflt == Omit {
+				notes = append(notes, "optional")
+			} else {
+				notes = append(notes, fmt.Sprintf("default %v", dflt))
+			}
+		} else if _, ok := c.fields[name].(flagPresentC); ok {
+			notes = append(notes, "optional")
+		} else {
+			notes = append(notes, "required")
+		}
+		if rule, ok := c.gates[name]; ok {
+			notes = append(notes, fmt.Sprintf("requires feature %q", rule.Feature))
+		}
+		fmt.Fprintf(buf, "%s%s: %s (%s)\n", indent, name, label, strings.Join(notes, ", "))
+		if fmap != nil {
+			describeFields(buf, *fmap, indent+"  ")
+		}
+	}
+}
+
+// describeChecker returns a description of c, and the FieldMap whose
+// fields should be described beneath it, if any.
+func describeChecker(c Checker) (string, *fieldMapC) {
+	switch c := c.(type) {
+	case Describer:
+		return c.Describe(), nil
+	case fieldMapC:
+		if c.strict {
+			return "strict map", &c
+		}
+		return "map", &c
+	case listC:
+		label, fmap := describeChecker(c.elem)
+		return "list of " + label, fmap
+	case mapC:
+		key, _ := describeChecker(c.key)
+		value, fmap := describeChecker(c.value)
+		return fmt.Sprintf("map of %s to %s", key, value), fmap
+	case stringMapC:
+		value, fmap := describeChecker(c.value)
+		return "map of string to " + value, fmap
+	case oneOfC:
+		return "one of " + describeCheckers(c.options), nil
+	case allOfC:
+		return "all of " + describeCheckers(c.checkers), nil
+	case notC:
+		label, _ := describeChecker(c.checker)
+		return "not " + label, nil
+	case nullableC:
+		label, fmap := describeChecker(c.checker)
+		return "nullable " + label, fmap
+	case transformC:
+		return describeChecker(c.checker)
+	case *lazyC:
+		// Describing the checker could recurse forever.
+		return "recursive value", nil
+	case withDefaultC:
+		label, fmap := describeChecker(c.checker)
+		return fmt.Sprintf("%s with default %v", label, c.dflt), fmap
+	case orDefaultC:
+		label, fmap := describeChecker(c.checker)
+		return fmt.Sprintf("%s or default %v", label, c.dflt), fmap
+	case constC:
+		return fmt.Sprintf("%#v", c.value), nil
+	case flagPresentC:
+		return "flag", nil
+	case mapSetC:
+		return fmt.Sprintf("map selected by %s", c.selector), nil
+	case nonEmptyStringC:
+		return "non-empty " + c.valueLabel, nil
+	case sregexpC, regexpPatternC:
+		return "regexp", nil
+	case timeDurationC, timeDurationStringC:
+		return "duration", nil
+	}
+	t := reflect.TypeOf(c)
+	if t.PkgPath() == reflect.TypeOf(anyC{}).PkgPath() {
+		return strings.TrimSuffix(t.Name(), "C"), nil
+	}
+	return t.String(), nil
+}
+
+func describeCheckers(checkers []Checker) string {
+	labels := make([]string, len(checkers))
+	for i, c := range checkers {
+		labels[i], _ = describeChecker(c)
+	}
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type describeSuite struct{}
+
+var _ = gc.Suite(&describeSuite{})
+
+type customChecker struct {
+	schema.Checker
+}
+
+func (customChecker) Describe() string {
+	return "custom thing"
+}
+
+type opaqueChecker struct {
+	schema.Checker
+}
+
+func (s *describeSuite) TestDescribe(c *gc.C) {
+	db := schema.StrictFieldMap(schema.Fields{
+		"host": schema.String(),
+		"port": schema.Int(),
+	}, schema.Defaults{
+		"port": 5432,
+	})
+	server := schema.FieldMap(schema.Fields{
+		"name": schema.NonEmptyString("name"),
+	}, nil)
+	sch := schema.FeatureGated(schema.FieldMap(schema.Fields{
+		"name":    schema.String(),
+		"db":      db,
+		"servers": schema.List(server),
+		"labels":  schema.StringMap(schema.String()),
+		"mode":    schema.OneOf(schema.Const("fast"), schema.Const("safe")),
+		"timeout": schema.TimeDuration(),
+		"debug":   schema.FlagPresent(),
+		"extra":   customChecker{},
+		"other":   opaqueChecker{},
+		"beta":    schema.Bool(),
+	}, schema.Defaults{
+		"labels":  schema.Omit,
+		"timeout": "5s",
+		"extra":   schema.Omit,
+		"other":   schema.Omit,
+		"beta":    false,
+	}), map[string]schema.FeatureRule{
+		"beta": {Feature: "beta"},
+	})
+
+	c.Check(schema.Describe(sch), gc.Equals, `
+beta: bool (default false, requires feature "beta")
+db: strict map (required)
+  host: string (required)
+  port: int (default 5432)
+debug: flag (optional)
+extra: custom thing (optional)
+labels: map of string to string (optional)
+mode: one of "fast", "safe" (required)
+name: string (required)
+other: schema_test.opaqueChecker (optional)
+servers: list of map (required)
+  name: non-empty name (required)
+timeout: duration (default 5s)`[1:])
+}
+
+func (s *describeSuite) TestDescribeNonFieldMap(c *gc.C) {
+	c.Check(schema.Describe(schema.List(schema.Map(schema.String(), schema.Float()))), gc.Equals, "list of map of string to float")
+	c.Check(schema.Describe(schema.AllOf(schema.String(), schema.Not(schema.Const("")))), gc.Equals, `all of string, not ""`)
+	c.Check(schema.Describe(schema.UUID()), gc.Equals, "uuid")
+	c.Check(schema.Describe(schema.List(schema.WithDefault(schema.Int(), 8080))), gc.Equals, "list of int with default 8080")
+	c.Check(schema.Describe(schema.OrDefault(schema.Int(), 3)), gc.Equals, "int or default 3")
+}
@@ -4,27 +4,64 @@
 package schema
 
 import (
+	"errors"
 	"fmt"
 )
 
-type error_ struct {
-	want string
-	got  interface{}
-	path []string
+// CoerceError is the error returned by the checkers in this package
+// when a value can't be coerced, which lets callers tell which value
+// failed, such as to report errors next to the fields of a form. Errors
+// from nested checkers are returned as they are, so the error refers to
+// the innermost value at fault.
+type CoerceError struct {
+	// Path holds the path of the value, as built by the checkers.
+	Path []string
+
+	// Expected describes the value that was expected, such as "int".
+	Expected string
+
+	// Got holds the value that was given, when known.
+	Got interface{}
+
+	// Message, if set, describes why the value was rejected, for
+	// values of an acceptable type that are invalid, such as a
+	// number out of range. It takes the place of Expected and Got
+	// in the error message.
+	Message string
 }
 
-func (e error_) Error() string {
-	path := pathAsPrefix(e.path)
-	if e.want == "" {
-		return fmt.Sprintf("%sunexpected value %#v", path, e.got)
+func (e CoerceError) Error() string {
+	path := pathAsPrefix(e.Path)
+	if e.Message != "" {
+		return path + e.Message
 	}
-	if e.got == nil {
-		return fmt.Sprintf("%sexpected %s, got nothing", path, e.want)
+	if e.Expected == "" {
+		return fmt.Sprintf("%sunexpected value %#v", path, e.Got)
 	}
-	return fmt.Sprintf("%sexpected %s, got %T(%#v)", path, e.want, e.got, e.got)
+	if e.Got == nil {
+		return fmt.Sprintf("%sexpected %s, got nothing", path, e.Expected)
+	}
+	return fmt.Sprintf("%sexpected %s, got %T(%#v)", path, e.Expected, e.Got, e.Got)
+}
+
+// FieldPath returns the path of the value that err refers to, if err
+// is or wraps a CoerceError. As with the path given to Coerce, map keys
+// are preceded by "." and list indexes are enclosed in "[" and "]", as
+// in []string{".", "servers", "[", "2", "]", ".", "port"}.
+func FieldPath(err error) ([]string, bool) {
+	var cerr CoerceError
+	if !errors.As(err, &cerr) {
+		return nil, false
+	}
+	return append([]string(nil), cerr.Path...), true
+}
+
+// errorf returns a CoerceError for the value at path, with the message
+// formatted as by fmt.Sprintf.
+func errorf(path []string, format string, args ...interface{}) error {
+	return CoerceError{Path: path, Message: fmt.Sprintf(format, args...)}
 }
 
 func parseError(path []string, expected string, err error) error {
-	prefix := pathAsPrefix(path)
-	return fmt.Errorf("%sconversion to %s: %s", prefix, expected, err.Error())
+	return errorf(path, "conversion to %s: %s", expected, err.Error())
 }
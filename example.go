@@ -0,0 +1,53 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"reflect"
+)
+
+// LikeExample returns a Checker inferred from the structure of example,
+// a representative value such as a sample document, so that validation
+// can be bootstrapped without writing a schema by hand. The checker is
+// inferred as follows:
+//
+//   - a map with string keys becomes a FieldMap in which every key of
+//     the example is a required field, with its checker inferred from
+//     the example value, recursively;
+//   - any other map becomes Map(Any(), Any());
+//   - a list becomes List(Any());
+//   - a bool becomes Bool(), an integer Int(), an unsigned integer
+//     Uint(), a float Float() and a string String();
+//   - nil and any other value become Any().
+//
+// This is a coarse structural check: values are only required to have
+// the same shape and types as in the example, and keys absent from the
+// example are dropped as by FieldMap.
+func LikeExample(example interface{}) Checker {
+	rv := reflect.ValueOf(example)
+	switch rv.Kind() {
+	case reflect.Map:
+		if !hasStrictStringKeys(rv) {
+			return Map(Any(), Any())
+		}
+		fields := make(Fields, rv.Len())
+		for _, k := range rv.MapKeys() {
+			fields[keyString(k)] = LikeExample(rv.MapIndex(k).Interface())
+		}
+		return FieldMap(fields, nil)
+	case reflect.Slice, reflect.Array:
+		return List(Any())
+	case reflect.Bool:
+		return Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Uint()
+	case reflect.Float32, reflect.Float64:
+		return Float()
+	case reflect.String:
+		return String()
+	}
+	return Any()
+}
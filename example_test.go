@@ -0,0 +1,78 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type exampleSuite struct{}
+
+var _ = gc.Suite(&exampleSuite{})
+
+func (s *exampleSuite) TestLikeExample(c *gc.C) {
+	sch := schema.LikeExample(map[string]interface{}{
+		"name":    "app",
+		"enabled": true,
+		"scale":   3,
+		"ratio":   0.5,
+		"tags":    []interface{}{"a"},
+		"owner":   nil,
+		"db": map[interface{}]interface{}{
+			"host": "localhost",
+			"port": 5432,
+		},
+	})
+
+	out, err := sch.Coerce(map[string]interface{}{
+		"name":    "other",
+		"enabled": false,
+		"scale":   int64(10),
+		"ratio":   1,
+		"tags":    []interface{}{1, "b"},
+		"owner":   "bob",
+		"db": map[string]interface{}{
+			"host":  "db.example.com",
+			"port":  int32(3306),
+			"extra": "dropped",
+		},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{
+		"name":    "other",
+		"enabled": false,
+		"scale":   int64(10),
+		"ratio":   float64(1),
+		"tags":    []interface{}{1, "b"},
+		"owner":   "bob",
+		"db": map[string]interface{}{
+			"host": "db.example.com",
+			"port": int64(3306),
+		},
+	})
+}
+
+func (s *exampleSuite) TestLikeExampleErrors(c *gc.C) {
+	sch := schema.LikeExample(map[string]interface{}{
+		"db": map[string]interface{}{"port": 5432},
+	})
+
+	_, err := sch.Coerce(map[string]interface{}{
+		"db": map[string]interface{}{"port": true},
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.db\.port: expected int, got bool\(true\)`)
+
+	_, err = sch.Coerce(map[string]interface{}{
+		"db": map[string]interface{}{},
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.db\.port: expected int, got nothing`)
+
+	_, err = sch.Coerce(map[string]interface{}{"db": []interface{}{}}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.db: expected map, got \[\]interface {}\(\[\]interface {}{}\)`)
+
+	_, err = schema.LikeExample([]string{"a"}).Coerce("a", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected list, got string\("a"\)`)
+}
@@ -4,8 +4,11 @@
 package schema
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 // Omit is a marker for FieldMap and StructFieldMap defaults parameter.
@@ -30,19 +33,209 @@ type Defaults map[string]interface{}
 //
 // The coerced output value has type map[string]interface{}.
 func FieldMap(fields Fields, defaults Defaults) Checker {
-	return fieldMapC{fields, defaults, false}
+	return fieldMapC{fields: fields, defaults: defaults}
 }
 
 // StrictFieldMap returns a Checker that acts as the one returned by FieldMap,
-// but the Checker returns an error if it encounters an unknown key.
+// but the Checker returns an error if it encounters an unknown key. When
+// there are several unknown keys, the error lists all of them.
 func StrictFieldMap(fields Fields, defaults Defaults) Checker {
-	return fieldMapC{fields, defaults, true}
+	return fieldMapC{fields: fields, defaults: defaults, strict: true}
+}
+
+// PreserveUnknown returns a copy of the given FieldMap checker that
+// copies keys without an associated checker verbatim into the coerced
+// map, instead of dropping them. Known keys are still coerced by their
+// checkers and defaults are applied as usual; as an unknown key never
+// matches a field, the two can't conflict.
+//
+// PreserveUnknown panics if fieldMap was not returned by FieldMap, as
+// a StrictFieldMap rejects unknown keys altogether.
+func PreserveUnknown(fieldMap Checker) Checker {
+	fmap, ok := fieldMap.(fieldMapC)
+	if !ok {
+		panic("PreserveUnknown got a non-FieldMap checker")
+	}
+	if fmap.strict {
+		panic("PreserveUnknown got a StrictFieldMap")
+	}
+	fmap.preserveUnknown = true
+	return fmap
+}
+
+// CaseInsensitiveKeys returns a copy of the given FieldMap or
+// StrictFieldMap checker that matches input keys against field names
+// ignoring case. The coerced map uses the field names as given in
+// fields. Input keys that differ only in case and match the same
+// field are an error, and a StrictFieldMap only rejects keys that
+// match no field in any case.
+//
+// CaseInsensitiveKeys panics if fieldMap was not returned by FieldMap
+// or StrictFieldMap, or if two of its field names differ only in case.
+func CaseInsensitiveKeys(fieldMap Checker) Checker {
+	fmap, ok := fieldMap.(fieldMapC)
+	if !ok {
+		panic("CaseInsensitiveKeys got a non-FieldMap checker")
+	}
+	fmap.foldedNames = make(map[string]string, len(fmap.fields))
+	for _, name := range fmap.fieldNames(true) {
+		folded := strings.ToLower(name)
+		if other, ok := fmap.foldedNames[folded]; ok {
+			panic(fmt.Sprintf("CaseInsensitiveKeys got fields %q and %q that differ only in case", other, name))
+		}
+		fmap.foldedNames[folded] = name
+	}
+	return fmap
+}
+
+// canonicalKeys returns a copy of the map rv with the keys that match
+// a field ignoring case replaced by the field name.
+func (c fieldMapC) canonicalKeys(rv reflect.Value, path []string) (reflect.Value, error) {
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keyString(keys[i]) < keyString(keys[j]) })
+	m := make(map[string]interface{}, len(keys))
+	given := make(map[string]string, len(keys))
+	for _, k := range keys {
+		ks := keyString(k)
+		if name, ok := c.foldedNames[strings.ToLower(ks)]; ok {
+			if other, ok := given[name]; ok {
+				return reflect.Value{}, errorf(path, "keys %q and %q differ only in case", other, ks)
+			}
+			given[name] = ks
+			ks = name
+		}
+		m[ks] = rv.MapIndex(k).Interface()
+	}
+	return reflect.ValueOf(m), nil
+}
+
+// Rename returns a Checker that accepts a map with string keys, renames
+// its keys according to mapping, from old to new names, and processes
+// the resulting map with inner, typically a FieldMap. This keeps
+// accepting documents that use the old names of renamed fields. As
+// inner only sees the new names, errors refer to those. Giving both the
+// old and the new name of a field is an error.
+func Rename(mapping map[string]string, inner Checker) Checker {
+	return renameC{mapping, inner}
+}
+
+type renameC struct {
+	mapping map[string]string
+	inner   Checker
+}
+
+func (c renameC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c renameC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, CoerceError{Expected: "map", Got: v, Path: path}
+	}
+	m, ok := asStringMap(v)
+	if !ok {
+		return nil, CoerceError{Expected: "map[string]", Got: v, Path: path}
+	}
+	olds := make([]string, 0, len(c.mapping))
+	for old := range c.mapping {
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+	for _, old := range olds {
+		value, ok := m[old]
+		if !ok {
+			continue
+		}
+		newName := c.mapping[old]
+		if _, ok := m[newName]; ok {
+			return nil, errorf(path, "both %q and its new name %q are set", old, newName)
+		}
+		delete(m, old)
+		m[newName] = value
+	}
+	return coerceField(c.inner, m, path, st)
+}
+
+// FeatureRule holds the rule for a field that depends on a feature, as
+// passed to FeatureGated.
+type FeatureRule struct {
+	// Feature holds the name of the feature.
+	Feature string
+	// Required specifies whether the field must be present when the
+	// feature is enabled.
+	Required bool
+}
+
+// FeatureGated returns a copy of the given FieldMap checker in which the
+// fields in rules are only allowed when their feature is enabled, so
+// that one schema can serve several feature configurations. Features
+// are enabled in the CoerceOptions passed to CoerceWithOptions, and are
+// all disabled when coercing with the Coerce method.
+//
+// When its feature is disabled, a field must be absent, and its default
+// is ignored. When the feature is enabled, the field is processed as
+// usual, but must be present if the rule says it is required.
+//
+// Features are passed on to nested FieldMaps, whether used directly as
+// fields or held by List, ListLen, Map, StringMap, OneOf, AllOf, ByType,
+// Nullable, OrSentinel, WithDefault, OrDefault, Transform, Rename,
+// Constrained and Lazy checkers, recursively. FieldMaps held by other
+// kinds of checker, such as a Tuple, see all features disabled.
+// FeatureGated panics if fieldMap was not returned by FieldMap or
+// StrictFieldMap, or if a rule refers to an unknown field.
+func FeatureGated(fieldMap Checker, rules map[string]FeatureRule) Checker {
+	fmap, ok := fieldMap.(fieldMapC)
+	if !ok {
+		panic("FeatureGated got a non-FieldMap checker")
+	}
+	for k := range rules {
+		if _, ok := fmap.fields[k]; !ok {
+			panic(fmt.Sprintf("FeatureGated got a rule for unknown field %q", k))
+		}
+	}
+	fmap.gates = rules
+	return fmap
+}
+
+// Deprecated returns a copy of the given FieldMap checker in which the
+// fields in messages are deprecated. Deprecated fields are processed
+// as usual, but when present in the input, a warning holding the path
+// of the field and its message, such as "use new_key instead", is
+// reported by CoerceWithWarnings. The Coerce method ignores warnings.
+//
+// Warnings are gathered from nested FieldMaps wherever features would
+// reach them (see FeatureGated), including FieldMaps within a List.
+// Deprecated panics if fieldMap was not returned by FieldMap or
+// StrictFieldMap, or if messages refers to an unknown field.
+func Deprecated(fieldMap Checker, messages map[string]string) Checker {
+	fmap, ok := fieldMap.(fieldMapC)
+	if !ok {
+		panic("Deprecated got a non-FieldMap checker")
+	}
+	for k := range messages {
+		if _, ok := fmap.fields[k]; !ok {
+			panic(fmt.Sprintf("Deprecated got a message for unknown field %q", k))
+		}
+	}
+	fmap.deprecated = messages
+	return fmap
 }
 
 type fieldMapC struct {
-	fields   Fields
-	defaults Defaults
-	strict   bool
+	fields          Fields
+	defaults        Defaults
+	strict          bool
+	preserveUnknown bool
+	gates           map[string]FeatureRule
+	foldedNames     map[string]string
+	deprecated      map[string]string
+}
+
+// gatedOff reports whether the field k is disabled by its feature.
+func (c fieldMapC) gatedOff(k string, st *coerceState) bool {
+	rule, ok := c.gates[k]
+	return ok && !st.opts.Features[rule.Feature]
 }
 
 var stringType = reflect.TypeOf("")
@@ -55,37 +248,360 @@ func hasStrictStringKeys(rv reflect.Value) bool {
 		return false
 	}
 	for _, k := range rv.MapKeys() {
-		if k.Elem().Type() != stringType {
+		if !k.Elem().IsValid() || k.Elem().Type() != stringType {
 			return false
 		}
 	}
 	return true
 }
 
+// keyString returns the string held by k, which must be a key of a map
+// for which hasStrictStringKeys holds.
+func keyString(k reflect.Value) string {
+	if k.Kind() == reflect.Interface {
+		k = k.Elem()
+	}
+	return k.String()
+}
+
+// CoerceStats holds statistics about the coercion of a value by a
+// FieldMap, as returned by CoerceWithStats.
+type CoerceStats struct {
+	// Fields holds the number of fields coerced by their checkers,
+	// whether they were present in the input or defaulted.
+	Fields int
+	// Defaults holds the number of fields that were set from defaults.
+	Defaults int
+	// Dependencies holds the number of Dependency constraints checked
+	// by Constrained checkers.
+	Dependencies int
+	// Unknown holds the number of input keys that had no checker.
+	Unknown int
+}
+
+// CoerceWithStats coerces v with c and returns statistics about the
+// work done alongside the result, for observability. Statistics are
+// gathered for c when it is a FieldMap, and for nested FieldMaps
+// wherever features would reach them (see FeatureGated), including
+// FieldMaps within a List. Of the options of a OneOf, only the one
+// that succeeds is counted.
+func CoerceWithStats(c Checker, v interface{}, path []string) (interface{}, CoerceStats, error) {
+	st := &coerceState{}
+	out, err := coerceField(c, v, path, st)
+	return out, st.stats, err
+}
+
+// CoerceWithWarnings coerces v with c and returns the warnings about
+// deprecated fields found in v alongside the result, sorted by path.
+// See Deprecated for the fields that are considered.
+func CoerceWithWarnings(c Checker, v interface{}, path []string) (interface{}, []string, error) {
+	st := &coerceState{}
+	out, err := coerceField(c, v, path, st)
+	sort.Strings(st.warnings)
+	return out, st.warnings, err
+}
+
+// CoerceOrdered coerces v with c, which must result in a
+// map[string]interface{} as returned by FieldMap, and returns the
+// coerced fields as a slice sorted by key, for callers needing a
+// stable order, such as when writing out canonical configuration.
+// Maps nested within the coerced map are left as they are.
+func CoerceOrdered(c Checker, v interface{}, path []string) ([]KeyValue, error) {
+	out, err := c.Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := out.(map[string]interface{})
+	if !ok {
+		return nil, CoerceError{Expected: "map[string]", Got: out, Path: path}
+	}
+	kvs := make([]KeyValue, 0, len(m))
+	for k, v := range m {
+		kvs = append(kvs, KeyValue{k, v})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs, nil
+}
+
+// ErrorList holds all the errors found by CoerceAll.
+type ErrorList struct {
+	errs []error
+}
+
+// Errors returns the errors held in the list, in the order they were
+// found.
+func (e *ErrorList) Errors() []error {
+	return e.errs
+}
+
+// Error implements the error interface, joining the messages of all the
+// errors in the list.
+func (e *ErrorList) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// CoerceAll coerces v with c, as c.Coerce(v, path) does, but rather than
+// stopping at the first error, carries on with the remaining fields and
+// reports all the errors found, so that they can all be fixed at once.
+// The error returned, if any, is an *ErrorList.
+//
+// Errors are collected from FieldMaps and Constrained checkers,
+// including unknown keys, defaults and constraints, wherever features
+// would reach them (see FeatureGated). Fields are then processed in
+// name order, so that errors are reported in a stable order. Other
+// checkers report at most one error, and a List or a map stops at its
+// first element in error.
+func CoerceAll(c Checker, v interface{}, path []string) (interface{}, error) {
+	st := &coerceState{accumulate: true}
+	out, err := coerceField(c, v, path, st)
+	if err != nil && err != errAccumulated {
+		st.errs = append(st.errs, err)
+	}
+	if len(st.errs) > 0 {
+		return nil, &ErrorList{st.errs}
+	}
+	return out, nil
+}
+
+// errAccumulated is returned by checkers sharing a coerceState when
+// they failed and the errors were accumulated in the state.
+var errAccumulated = errors.New("errors accumulated")
+
+// coerceState holds the state shared by the checkers implementing
+// stateCoercer while coercing a value.
+type coerceState struct {
+	stats      CoerceStats
+	opts       CoerceOptions
+	accumulate bool
+	errs       []error
+	warnings   []string
+}
+
+// warn records a warning about the deprecated field at path.
+func (st *coerceState) warn(path []string, msg string) {
+	w := pathAsString(path) + " is deprecated"
+	if msg != "" {
+		w += ": " + msg
+	}
+	st.warnings = append(st.warnings, w)
+}
+
+// fail returns err, unless errors are being accumulated in st, in which
+// case it records err and returns nil so that coercion carries on.
+func (st *coerceState) fail(err error) error {
+	if st == nil || !st.accumulate {
+		return err
+	}
+	if err != errAccumulated {
+		st.errs = append(st.errs, err)
+	}
+	return nil
+}
+
+// branch returns a state for trying a checker that may fail without
+// failing the coercion, as OneOf does, or nil if st is nil. Errors are
+// not accumulated in the branch, and what it gathers is only added to
+// st by merge.
+func (st *coerceState) branch() *coerceState {
+	if st == nil {
+		return nil
+	}
+	return &coerceState{opts: st.opts}
+}
+
+// merge adds the statistics and warnings gathered in the branch b to st.
+func (st *coerceState) merge(b *coerceState) {
+	if st == nil {
+		return
+	}
+	st.stats.Fields += b.stats.Fields
+	st.stats.Defaults += b.stats.Defaults
+	st.stats.Dependencies += b.stats.Dependencies
+	st.stats.Unknown += b.stats.Unknown
+	st.warnings = append(st.warnings, b.warnings...)
+}
+
+// accumulating reports whether errors are being accumulated in st.
+func (st *coerceState) accumulating() bool {
+	return st != nil && st.accumulate
+}
+
+// stateCoercer is implemented by checkers that share the coerceState of
+// a coercion with the checkers they use, such as FieldMaps and the
+// lists and maps that may hold them.
+type stateCoercer interface {
+	coerce(v interface{}, path []string, st *coerceState) (interface{}, error)
+}
+
+// coerceField coerces value with checker, sharing st with it if it
+// implements stateCoercer.
+func coerceField(checker Checker, value interface{}, path []string, st *coerceState) (interface{}, error) {
+	if st == nil {
+		return checker.Coerce(value, path)
+	}
+	if s, ok := value.(string); ok && st.opts.TrimStrings {
+		value = strings.TrimSpace(s)
+	}
+	if st.opts.NoStringConversion {
+		if label, ok := convertsStrings(checker); ok && value != nil && reflect.TypeOf(value).Kind() == reflect.String {
+			return nil, CoerceError{Expected: label, Got: value, Path: path}
+		}
+	}
+	if c, ok := checker.(stateCoercer); ok {
+		return c.coerce(value, path, st)
+	}
+	return checker.Coerce(value, path)
+}
+
+// coerceDefault acts as coerceField for a default value, which is
+// exempt from the options about input values, such as TrimStrings.
+func coerceDefault(checker Checker, dflt interface{}, path []string, st *coerceState) (interface{}, error) {
+	opts := st.opts
+	st.opts.TrimStrings = false
+	st.opts.NoStringConversion = false
+	defer func() { st.opts = opts }()
+	return coerceField(checker, dflt, path, st)
+}
+
+// convertsStrings reports whether checker parses strings holding
+// values of another type, and if so returns the label of that type.
+func convertsStrings(checker Checker) (string, bool) {
+	switch checker.(type) {
+	case boolC:
+		return "bool", true
+	case intC:
+		return "int", true
+	case uintC, forceUintC:
+		return "uint", true
+	case forceIntC:
+		return "number", true
+	case floatC:
+		return "float", true
+	}
+	return "", false
+}
+
 func (c fieldMapC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c fieldMapC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	if st == nil {
+		st = &coerceState{}
+	}
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Map {
-		return nil, error_{"map", v, path}
+		return nil, CoerceError{Expected: "map", Got: v, Path: path}
 	}
 	if !hasStrictStringKeys(rv) {
-		return nil, error_{"map[string]", v, path}
+		return nil, CoerceError{Expected: "map[string]", Got: v, Path: path}
+	}
+	if c.foldedNames != nil {
+		var err error
+		if rv, err = c.canonicalKeys(rv, path); err != nil {
+			return nil, err
+		}
 	}
 
-	if c.strict {
-		for _, k := range rv.MapKeys() {
-			ks := k.String()
-			if _, ok := c.fields[ks]; !ok {
-				return nil, fmt.Errorf("%sunknown key %q (value %#v)", pathAsPrefix(path), ks, rv.MapIndex(k).Interface())
+	nerrs := len(st.errs)
+	keys := rv.MapKeys()
+	if st.accumulate {
+		sort.Slice(keys, func(i, j int) bool { return keyString(keys[i]) < keyString(keys[j]) })
+	}
+	if c.strict || st.opts.StrictKeys && !c.preserveUnknown {
+		// Report all unknown keys at once, so that several typos
+		// can be fixed in one go.
+		var unknown []reflect.Value
+		for _, k := range keys {
+			if _, ok := c.fields[keyString(k)]; !ok {
+				unknown = append(unknown, k)
+			}
+		}
+		var err error
+		switch len(unknown) {
+		case 0:
+		case 1:
+			err = errorf(path, "unknown key %q (value %#v)", keyString(unknown[0]), rv.MapIndex(unknown[0]).Interface())
+		default:
+			names := make([]string, len(unknown))
+			for i, k := range unknown {
+				names[i] = keyString(k)
+			}
+			sort.Strings(names)
+			err = errorf(path, "unknown keys %q", names)
+		}
+		if err != nil {
+			if err := st.fail(err); err != nil {
+				return nil, err
 			}
 		}
 	}
 
 	vpath := append(path, ".", "?")
+	// fieldPath returns the path of the field k. When accumulating
+	// errors, every field needs its own path, as errors keep hold of it.
+	fieldPath := func(k string) []string {
+		if st.accumulate {
+			return append(path[:len(path):len(path)], ".", k)
+		}
+		vpath[len(vpath)-1] = k
+		return vpath
+	}
 
 	out := make(map[string]interface{}, rv.Len())
-	for k, checker := range c.fields {
+	for _, k := range keys {
+		ks := keyString(k)
+		if _, ok := c.fields[ks]; !ok {
+			st.stats.Unknown++
+			if c.preserveUnknown {
+				out[ks] = rv.MapIndex(k).Interface()
+			}
+		}
+	}
+	failed := make(map[string]bool)
+	for _, k := range c.fieldNames(st.accumulate) {
+		checker := c.fields[k]
 		valuev := rv.MapIndex(reflect.ValueOf(k))
+		if msg, ok := c.deprecated[k]; ok && valuev.IsValid() {
+			st.warn(append(path[:len(path):len(path)], ".", k), msg)
+		}
+		if _, ok := checker.(flagPresentC); ok {
+			st.stats.Fields++
+			out[k] = valuev.IsValid()
+			continue
+		}
+		if fc, ok := checker.(forbiddenC); ok && valuev.IsValid() {
+			// Forbidden rejects any present key, even with a nil
+			// value, which it can't tell from a missing one.
+			if err := st.fail(fc.reject(fieldPath(k))); err != nil {
+				return nil, err
+			}
+			failed[k] = true
+			continue
+		}
+		if c.gatedOff(k, st) {
+			if valuev.IsValid() {
+				err := errorf(append(path[:len(path):len(path)], ".", k), "field not allowed as feature %q is disabled", c.gates[k].Feature)
+				if err := st.fail(err); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if c.gates[k].Required && !valuev.IsValid() {
+			err := errorf(append(path[:len(path):len(path)], ".", k), "field required as feature %q is enabled", c.gates[k].Feature)
+			if err := st.fail(err); err != nil {
+				return nil, err
+			}
+			failed[k] = true
+			continue
+		}
 		var value interface{}
+		coerce := coerceField
 		if valuev.IsValid() {
 			value = valuev.Interface()
 		} else if dflt, ok := c.defaults[k]; ok {
@@ -93,11 +609,17 @@ func (c fieldMapC) Coerce(v interface{}, path []string) (interface{}, error) {
 				continue
 			}
 			value = dflt
+			coerce = coerceDefault
+			st.stats.Defaults++
 		}
-		vpath[len(vpath)-1] = k
-		newv, err := checker.Coerce(value, vpath)
+		st.stats.Fields++
+		newv, err := coerce(checker, value, fieldPath(k), st)
 		if err != nil {
-			return nil, err
+			if err := st.fail(err); err != nil {
+				return nil, err
+			}
+			failed[k] = true
+			continue
 		}
 		out[k] = newv
 	}
@@ -105,22 +627,110 @@ func (c fieldMapC) Coerce(v interface{}, path []string) (interface{}, error) {
 		if v == Omit {
 			continue
 		}
-		if _, ok := out[k]; !ok {
+		if _, ok := out[k]; !ok && !failed[k] && !c.gatedOff(k, st) {
 			checker, ok := c.fields[k]
 			if !ok {
 				return nil, fmt.Errorf("got default value for unknown field %q", k)
 			}
-			vpath[len(vpath)-1] = k
-			newv, err := checker.Coerce(v, vpath)
+			newv, err := checker.Coerce(v, fieldPath(k))
 			if err != nil {
-				return nil, err
+				if err := st.fail(err); err != nil {
+					return nil, err
+				}
+				continue
 			}
 			out[k] = newv
 		}
 	}
+	if len(st.errs) > nerrs {
+		return nil, errAccumulated
+	}
 	return out, nil
 }
 
+// fieldNames returns the names of the fields of c, sorted if requested.
+func (c fieldMapC) fieldNames(sorted bool) []string {
+	names := make([]string, 0, len(c.fields))
+	for k := range c.fields {
+		names = append(names, k)
+	}
+	if sorted {
+		sort.Strings(names)
+	}
+	return names
+}
+
+// FlagPresent returns a Checker for FieldMap fields whose mere presence
+// means true, such as a "debug:" key with no value in YAML. Within a
+// FieldMap, the field coerces to true whenever the key exists in the
+// input, whatever its value, including nil, and to false when it
+// doesn't. Defaults for the field have no effect, and in a
+// StrictFieldMap the key is known as any other field.
+//
+// Used on its own, the Checker cannot tell a missing value from a nil
+// one, and returns whether the value is non-nil.
+func FlagPresent() Checker {
+	return flagPresentC{}
+}
+
+type flagPresentC struct{}
+
+func (c flagPresentC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return v != nil, nil
+}
+
+// OneOfValue holds the field set in a group of fields checked by
+// ProtoOneOf, and its coerced value.
+type OneOfValue struct {
+	Field string
+	Value interface{}
+}
+
+// ProtoOneOf returns a Checker that accepts a map in which exactly one
+// of the keys in fields is present, mirroring a protobuf oneof. The
+// value of that key is processed with its checker, and the coerced
+// output value is a OneOfValue holding the key and the coerced value.
+// Keys not in fields are ignored, so that the group can be checked
+// alongside a FieldMap covering the other keys of the same map.
+func ProtoOneOf(fields map[string]Checker) Checker {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return protoOneOfC{fields, names}
+}
+
+type protoOneOfC struct {
+	fields map[string]Checker
+	names  []string
+}
+
+func (c protoOneOfC) Coerce(v interface{}, path []string) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, CoerceError{Expected: "map", Got: v, Path: path}
+	}
+	if !hasStrictStringKeys(rv) {
+		return nil, CoerceError{Expected: "map[string]", Got: v, Path: path}
+	}
+	var set []string
+	for _, name := range c.names {
+		if rv.MapIndex(reflect.ValueOf(name)).IsValid() {
+			set = append(set, name)
+		}
+	}
+	if len(set) != 1 {
+		return nil, errorf(path, "expected exactly one of %q, got %q", c.names, set)
+	}
+	name := set[0]
+	newv, err := c.fields[name].Coerce(rv.MapIndex(reflect.ValueOf(name)).Interface(), append(path[:len(path):len(path)], ".", name))
+	if err != nil {
+		return nil, err
+	}
+	return OneOfValue{name, newv}, nil
+}
+
 // FieldMapSet returns a Checker that accepts a map value checked
 // against one of several FieldMap checkers.  The actual checker
 // used is the first one whose checker associated with the selector
@@ -140,31 +750,69 @@ func FieldMapSet(selector string, maps []Checker) Checker {
 			panic("FieldMapSet got a non-FieldMap checker")
 		}
 	}
-	return mapSetC{selector, fmaps}
+	return mapSetC{selector: selector, fmaps: fmaps}
+}
+
+// OverlappingFieldMapSet returns a Checker that acts as the one returned
+// by FieldMapSet, but allows several FieldMap checkers to accept the
+// same selector value. Rather than using the first of them, each one is
+// tried in turn and the result of the first that processes the whole
+// map successfully is returned. If none of them do, the error reports
+// why each one failed.
+//
+// As the map may be processed once for every checker sharing a
+// selector value, coercion is slower than with FieldMapSet when many
+// checkers overlap.
+func OverlappingFieldMapSet(selector string, maps []Checker) Checker {
+	c := FieldMapSet(selector, maps).(mapSetC)
+	c.overlapping = true
+	return c
 }
 
 type mapSetC struct {
-	selector string
-	fmaps    []fieldMapC
+	selector    string
+	fmaps       []fieldMapC
+	overlapping bool
 }
 
 func (c mapSetC) Coerce(v interface{}, path []string) (interface{}, error) {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Map {
-		return nil, error_{"map", v, path}
+		return nil, CoerceError{Expected: "map", Got: v, Path: path}
+	}
+	if !hasStrictStringKeys(rv) {
+		return nil, CoerceError{Expected: "map[string]", Got: v, Path: path}
 	}
 
 	var selector interface{}
 	selectorv := rv.MapIndex(reflect.ValueOf(c.selector))
 	if selectorv.IsValid() {
 		selector = selectorv.Interface()
+		var errs []error
 		for _, fmap := range c.fmaps {
 			_, err := fmap.fields[c.selector].Coerce(selector, path)
 			if err != nil {
 				continue
 			}
-			return fmap.Coerce(v, path)
+			if !c.overlapping {
+				return fmap.Coerce(v, path)
+			}
+			out, err := fmap.Coerce(v, path)
+			if err == nil {
+				return out, nil
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 1 {
+			return nil, errs[0]
+		}
+		if len(errs) > 1 {
+			msgs := make([]string, len(errs))
+			for i, err := range errs {
+				msgs[i] = err.Error()
+			}
+			return nil, errorf(path, "no map for selector %#v matched: %s", selector, strings.Join(msgs, "; "))
 		}
 	}
-	return nil, error_{"supported selector", selector, append(path, ".", c.selector)}
+	return nil, CoerceError{Expected: "supported selector", Got: selector, Path: append(path, ".", c.selector)}
 }
@@ -0,0 +1,162 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FileMode returns a Checker that accepts Unix file permissions, and
+// returns them as an os.FileMode. Permissions may be given as a string
+// holding an octal number such as "0644", "644" or "0o644", as a
+// symbolic string such as "rwxr-xr-x", optionally preceded by "-" as
+// shown by ls, or as an integer such as 0644. Only the permission bits
+// are supported, so the mode must lie between 0 and 0777.
+func FileMode() Checker {
+	return fileModeC{}
+}
+
+type fileModeC struct{}
+
+func (c fileModeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil {
+		return nil, CoerceError{Expected: "file mode", Got: v, Path: path}
+	}
+	var mode int64
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		mode = rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > uint64(os.ModePerm) {
+			return nil, errorf(path, "file mode %#o out of range [0, 0777]", rv.Uint())
+		}
+		mode = int64(rv.Uint())
+	case reflect.String:
+		m, err := parseFileMode(rv.String())
+		if err != nil {
+			return nil, errorf(path, "%v", err)
+		}
+		mode = m
+	default:
+		return nil, CoerceError{Expected: "file mode", Got: v, Path: path}
+	}
+	if mode < 0 || mode > int64(os.ModePerm) {
+		return nil, errorf(path, "file mode %#o out of range [0, 0777]", mode)
+	}
+	return os.FileMode(mode), nil
+}
+
+func parseFileMode(s string) (int64, error) {
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		m, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O"), 8, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid octal file mode %q", s)
+		}
+		return m, nil
+	}
+	sym := s
+	if len(sym) == 10 && sym[0] == '-' {
+		sym = sym[1:]
+	}
+	if len(sym) != 9 {
+		return 0, fmt.Errorf("invalid symbolic file mode %q: expected 9 characters such as \"rwxr-xr-x\"", s)
+	}
+	var m int64
+	for i := 0; i < 9; i++ {
+		m <<= 1
+		switch sym[i] {
+		case "rwx"[i%3]:
+			m |= 1
+		case '-':
+		default:
+			return 0, fmt.Errorf("invalid symbolic file mode %q: expected %q or '-' at position %d, got %q", s, "rwx"[i%3], i+1, sym[i])
+		}
+	}
+	return m, nil
+}
+
+// PathOption holds an option for Path.
+type PathOption int
+
+const (
+	// AbsoluteOnly makes Path reject relative paths.
+	AbsoluteOnly PathOption = iota + 1
+	// MustExist makes Path reject paths that don't exist.
+	MustExist
+	// MustBeDir makes Path reject paths that aren't existing
+	// directories.
+	MustBeDir
+	// MustBeFile makes Path reject paths that aren't existing regular
+	// files.
+	MustBeFile
+)
+
+// Path returns a Checker that accepts a non-empty string holding a
+// file system path, and returns it cleaned by filepath.Clean. The
+// AbsoluteOnly option rejects relative paths.
+//
+// The MustExist, MustBeDir and MustBeFile options check the path
+// against the local file system. They may be combined. They follow
+// symbolic links, so the result depends on the state of the file system
+// at the time the value is coerced, and they only make sense when the
+// value is coerced on the machine where the path is used.
+func Path(options ...PathOption) Checker {
+	var c pathC
+	for _, opt := range options {
+		switch opt {
+		case AbsoluteOnly:
+			c.absolute = true
+		case MustExist:
+			c.mustExist = true
+		case MustBeDir:
+			c.mustBeDir = true
+		case MustBeFile:
+			c.mustBeFile = true
+		}
+	}
+	return c
+}
+
+type pathC struct {
+	absolute   bool
+	mustExist  bool
+	mustBeDir  bool
+	mustBeFile bool
+}
+
+func (c pathC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "path", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	if s == "" {
+		return nil, CoerceError{Expected: "non-empty path", Got: v, Path: path}
+	}
+	p := filepath.Clean(s)
+	if c.absolute && !filepath.IsAbs(p) {
+		return nil, errorf(path, "%q is not an absolute path", p)
+	}
+	if !c.mustExist && !c.mustBeDir && !c.mustBeFile {
+		return p, nil
+	}
+	info, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return nil, errorf(path, "%q does not exist", p)
+	}
+	if err != nil {
+		return nil, errorf(path, "%v", err)
+	}
+	if c.mustBeDir && !info.IsDir() {
+		return nil, errorf(path, "%q is not a directory", p)
+	}
+	if c.mustBeFile && !info.Mode().IsRegular() {
+		return nil, errorf(path, "%q is not a regular file", p)
+	}
+	return p, nil
+}
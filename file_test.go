@@ -0,0 +1,128 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"os"
+	"path/filepath"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type fileSuite struct{}
+
+var _ = gc.Suite(&fileSuite{})
+
+func (s *fileSuite) TestFileMode(c *gc.C) {
+	sch := schema.FileMode()
+
+	tests := []struct {
+		in  interface{}
+		out os.FileMode
+	}{
+		{"0644", 0644},
+		{"755", 0755},
+		{"0o600", 0600},
+		{"0", 0},
+		{"rwxr-xr-x", 0755},
+		{"-rw-r-----", 0640},
+		{"---------", 0},
+		{0644, 0644},
+		{uint32(0700), 0700},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %v", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	errTests := []struct {
+		in  interface{}
+		err string
+	}{
+		{"0689", `<path>: invalid octal file mode "0689"`},
+		{"64x", `<path>: invalid octal file mode "64x"`},
+		{"rwxr-xr", `<path>: invalid symbolic file mode "rwxr-xr": expected 9 characters such as "rwxr-xr-x"`},
+		{"rwxrwxrwz", `<path>: invalid symbolic file mode "rwxrwxrwz": expected 'x' or '-' at position 9, got 'z'`},
+		{"wrxr-xr-x", `<path>: invalid symbolic file mode "wrxr-xr-x": expected 'r' or '-' at position 1, got 'w'`},
+		{"01777", `<path>: file mode 01777 out of range \[0, 0777\]`},
+		{-1, `<path>: file mode -01 out of range \[0, 0777\]`},
+		{uint(01000), `<path>: file mode 01000 out of range \[0, 0777\]`},
+		{true, `<path>: expected file mode, got bool\(true\)`},
+		{nil, `<path>: expected file mode, got nothing`},
+	}
+	for i, test := range errTests {
+		c.Logf("test %d: %v", i, test.in)
+		_, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *fileSuite) TestPath(c *gc.C) {
+	sch := schema.Path()
+	for in, out := range map[string]string{
+		"a/b/../c/":  "a/c",
+		"/etc//juju": "/etc/juju",
+		".":          ".",
+		"/no/such":   "/no/such",
+	} {
+		result, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(result, gc.Equals, out)
+	}
+
+	_, err := sch.Coerce("", aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected non-empty path, got string\(""\)`)
+	_, err = sch.Coerce(42, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected path, got int\(42\)`)
+
+	sch = schema.Path(schema.AbsoluteOnly)
+	out, err := sch.Coerce("/etc/../var", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "/var")
+	_, err = sch.Coerce("etc/juju", aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: "etc/juju" is not an absolute path`)
+}
+
+func (s *fileSuite) TestPathExistence(c *gc.C) {
+	dir := c.MkDir()
+	file := filepath.Join(dir, "file")
+	err := os.WriteFile(file, nil, 0644)
+	c.Assert(err, gc.IsNil)
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		options []schema.PathOption
+		in      string
+		err     string
+	}{
+		{[]schema.PathOption{schema.MustExist}, dir, ""},
+		{[]schema.PathOption{schema.MustExist}, file + "/", ""},
+		{[]schema.PathOption{schema.MustExist}, missing, `<path>: ".*/missing" does not exist`},
+		{[]schema.PathOption{schema.MustBeDir}, dir + "/.", ""},
+		{[]schema.PathOption{schema.MustBeDir}, file, `<path>: ".*/file" is not a directory`},
+		{[]schema.PathOption{schema.MustBeDir}, missing, `<path>: ".*/missing" does not exist`},
+		{[]schema.PathOption{schema.MustBeFile}, file, ""},
+		{[]schema.PathOption{schema.MustBeFile}, dir, `<path>: ".*" is not a regular file`},
+		{[]schema.PathOption{schema.MustBeFile}, missing, `<path>: ".*/missing" does not exist`},
+		// Options combine, whatever their order.
+		{[]schema.PathOption{schema.MustBeFile, schema.MustExist}, dir, `<path>: ".*" is not a regular file`},
+		{[]schema.PathOption{schema.MustExist, schema.MustBeDir}, file, `<path>: ".*/file" is not a directory`},
+		{[]schema.PathOption{schema.MustBeDir, schema.AbsoluteOnly}, dir, ""},
+		{[]schema.PathOption{schema.MustExist, schema.AbsoluteOnly}, "file", `<path>: "file" is not an absolute path`},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %v %s", i, test.options, test.in)
+		out, err := schema.Path(test.options...).Coerce(test.in, aPath)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.Equals, filepath.Clean(test.in))
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"encoding/json"
+)
+
+// FuzzCoerce decodes data as a JSON document and coerces the result
+// with c. It is meant to be called from fuzz tests exercising a schema
+// with arbitrary input: no checker in this package panics on malformed
+// input, so any panic points at a bug in c itself. Data that is not
+// valid JSON is reported as an error.
+func FuzzCoerce(c Checker, data []byte) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return c.Coerce(v, nil)
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/juju/schema"
+)
+
+// fuzzSchema exercises most of the built-in checkers.
+var fuzzSchema = schema.FieldMapSet("kind", []schema.Checker{
+	schema.StrictFieldMap(schema.Fields{
+		"kind":     schema.Const("server"),
+		"name":     schema.NonEmptyString("name"),
+		"port":     schema.ForceInt(),
+		"weight":   schema.Float(),
+		"enabled":  schema.Bool(),
+		"id":       schema.UUID(),
+		"endpoint": schema.URL(),
+		"memory":   schema.Size(),
+		"started":  schema.Time(),
+		"timeout":  schema.TimeDuration(),
+		"tags":     schema.List(schema.Stringified()),
+		"labels":   schema.StringMap(schema.String()),
+		"limits":   schema.Map(schema.String(), schema.Uint()),
+		"image":    schema.ImageReference(),
+		"order":    schema.OrderedMap(schema.ForceUint()),
+	}, schema.Defaults{
+		"port":     8080,
+		"weight":   schema.Omit,
+		"enabled":  schema.Omit,
+		"id":       schema.Omit,
+		"endpoint": schema.Omit,
+		"memory":   schema.Omit,
+		"started":  schema.Omit,
+		"timeout":  schema.Omit,
+		"tags":     schema.Omit,
+		"labels":   schema.Omit,
+		"limits":   schema.Omit,
+		"image":    schema.Omit,
+		"order":    schema.Omit,
+	}),
+	schema.FieldMap(schema.Fields{
+		"kind":   schema.Const("layered"),
+		"layers": schema.Merged(schema.StringMap(schema.Any())),
+		"filter": schema.OneOf(schema.SimpleRegexp(), schema.Nil("")),
+	}, nil),
+})
+
+func FuzzCoerce(f *testing.F) {
+	for _, seed := range []string{
+		`{"kind": "server", "name": "a", "port": "80", "tags": [1, true, "x"]}`,
+		`{"kind": "server", "name": "a", "labels": {"a": "b"}, "limits": {"cpu": 2}}`,
+		`{"kind": "server", "name": "a", "memory": "1G", "timeout": "1m", "order": [{"a": 1}]}`,
+		`{"kind": "layered", "layers": [{"a": 1}, {"a": {"b": 2}}], "filter": "[a-z]+"}`,
+		`{"kind": 1}`,
+		`[1, 2, 3]`,
+		`null`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Errors are expected, panics are not.
+		schema.FuzzCoerce(fuzzSchema, data)
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ImageRef holds the components of an OCI image reference, as in
+// "registry.example.com:5000/team/app:1.0@sha256:...". Registry, Tag and
+// Digest are empty when absent from the reference.
+type ImageRef struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// String returns the reference in its textual form.
+func (r ImageRef) String() string {
+	s := r.Repository
+	if r.Registry != "" {
+		s = r.Registry + "/" + s
+	}
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// ImageReference returns a Checker that accepts a string holding an
+// OCI (Docker) image reference and returns its components as an
+// ImageRef. The error message names the component that is invalid.
+func ImageReference() Checker {
+	return imageReferenceC{}
+}
+
+type imageReferenceC struct{}
+
+var (
+	imageDomainRegexp    = regexp.MustCompile(`^(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?$`)
+	imageComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]+)[a-z0-9]+)*$`)
+	imageTagRegexp       = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	imageDigestRegexp    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+)
+
+// Coerce implements Checker Coerce method.
+func (c imageReferenceC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	ref, err := parseImageRef(s)
+	if err != nil {
+		return nil, errorf(path, "invalid image reference %q: %v", s, err)
+	}
+	return ref, nil
+}
+
+func parseImageRef(s string) (ImageRef, error) {
+	var ref ImageRef
+	if s == "" {
+		return ref, fmt.Errorf("empty reference")
+	}
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		s, ref.Digest = s[:i], s[i+1:]
+		if !imageDigestRegexp.MatchString(ref.Digest) {
+			return ref, fmt.Errorf("invalid digest")
+		}
+	}
+	if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		s, ref.Tag = s[:i], s[i+1:]
+		if !imageTagRegexp.MatchString(ref.Tag) {
+			return ref, fmt.Errorf("invalid tag")
+		}
+	}
+	if len(s) > 255 {
+		return ref, fmt.Errorf("name longer than 255 characters")
+	}
+	// As with docker, the first component is only a registry if it
+	// looks like a host name rather than a repository path component.
+	if i := strings.Index(s, "/"); i >= 0 {
+		first := s[:i]
+		if first == "localhost" || strings.ContainsAny(first, ".:") || strings.ToLower(first) != first {
+			if !imageDomainRegexp.MatchString(first) {
+				return ref, fmt.Errorf("invalid registry")
+			}
+			ref.Registry, s = first, s[i+1:]
+		}
+	}
+	for _, component := range strings.Split(s, "/") {
+		if !imageComponentRegexp.MatchString(component) {
+			return ref, fmt.Errorf("invalid repository")
+		}
+	}
+	ref.Repository = s
+	return ref, nil
+}
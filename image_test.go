@@ -0,0 +1,84 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type imageSuite struct{}
+
+var _ = gc.Suite(&imageSuite{})
+
+const testDigest = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func (s *imageSuite) TestImageReference(c *gc.C) {
+	sch := schema.ImageReference()
+
+	tests := []struct {
+		in  string
+		out schema.ImageRef
+	}{{
+		in:  "ubuntu",
+		out: schema.ImageRef{Repository: "ubuntu"},
+	}, {
+		in:  "ubuntu:22.04",
+		out: schema.ImageRef{Repository: "ubuntu", Tag: "22.04"},
+	}, {
+		in:  "jujusolutions/jujud-operator:3.1.0",
+		out: schema.ImageRef{Repository: "jujusolutions/jujud-operator", Tag: "3.1.0"},
+	}, {
+		in:  "registry.example.com:5000/team/app:v1@" + testDigest,
+		out: schema.ImageRef{Registry: "registry.example.com:5000", Repository: "team/app", Tag: "v1", Digest: testDigest},
+	}, {
+		in:  "localhost/app@" + testDigest,
+		out: schema.ImageRef{Registry: "localhost", Repository: "app", Digest: testDigest},
+	}}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+		c.Check(out.(schema.ImageRef).String(), gc.Equals, test.in)
+	}
+}
+
+func (s *imageSuite) TestImageReferenceErrors(c *gc.C) {
+	sch := schema.ImageReference()
+
+	tests := []struct {
+		in  string
+		err string
+	}{{
+		in:  "",
+		err: `<path>: invalid image reference "": empty reference`,
+	}, {
+		in:  "ubuntu:-bad",
+		err: `<path>: invalid image reference "ubuntu:-bad": invalid tag`,
+	}, {
+		in:  "ubuntu@sha256:abc",
+		err: `<path>: invalid image reference "ubuntu@sha256:abc": invalid digest`,
+	}, {
+		in:  "Ubuntu",
+		err: `<path>: invalid image reference "Ubuntu": invalid repository`,
+	}, {
+		in:  "-bad-.example.com/app",
+		err: `<path>: invalid image reference "-bad-.example.com/app": invalid registry`,
+	}, {
+		in:  "team//app",
+		err: `<path>: invalid image reference "team//app": invalid repository`,
+	}}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(out, gc.IsNil)
+		c.Check(err.Error(), gc.Equals, test.err)
+	}
+
+	out, err := sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected string, got int\(42\)`)
+}
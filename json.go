@@ -0,0 +1,374 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CanonicalJSON returns a Checker that accepts a string holding a JSON
+// document and returns it re-encoded in canonical form: object keys are
+// sorted at every level, insignificant whitespace is removed and numbers
+// are written in a canonical form, so that 1, 1.0 and 1e0 are all
+// written as 1. Numbers are rewritten exactly, without rounding through
+// float64: integer digits are kept while the decimal point is within 21
+// digits of the start of the number, with exponent notation used for
+// larger and very small numbers, as JavaScript does. Equivalent
+// documents therefore coerce to identical strings, which makes the
+// result suitable for comparison and hashing.
+func CanonicalJSON() Checker {
+	return canonicalJSONC{}
+}
+
+type canonicalJSONC struct{}
+
+func (c canonicalJSONC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	doc, err := decodeJSON(reflect.ValueOf(v).String())
+	if err != nil {
+		return nil, errorf(path, "invalid JSON: %v", err)
+	}
+	doc, err = canonicalNumbers(doc)
+	if err != nil {
+		return nil, errorf(path, "invalid JSON: %v", err)
+	}
+	data, err := canonicalJSON(doc)
+	if err != nil {
+		return nil, errorf(path, "invalid JSON: %v", err)
+	}
+	return string(data), nil
+}
+
+// canonicalNumbers returns the document v, as returned by decodeJSON,
+// with every number within it in canonical form.
+func canonicalNumbers(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return canonicalNumber(string(v))
+	case map[string]interface{}:
+		for k, elem := range v {
+			elem, err := canonicalNumbers(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = elem
+		}
+	case []interface{}:
+		for i, elem := range v {
+			elem, err := canonicalNumbers(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = elem
+		}
+	}
+	return v, nil
+}
+
+// canonicalNumber returns the JSON number in canonical form, as
+// described by CanonicalJSON.
+func canonicalNumber(number string) (json.Number, error) {
+	neg := strings.HasPrefix(number, "-")
+	s := strings.TrimPrefix(number, "-")
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil || e > 1e9 || e < -1e9 {
+			return "", fmt.Errorf("number %s out of range", number)
+		}
+		exp = e
+		s = s[:i]
+	}
+	// The value is digits × 10^exp.
+	digits := s
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		exp -= len(s) - i - 1
+	}
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return "0", nil
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed)
+	digits = trimmed
+
+	// n is the position of the decimal point relative to the start
+	// of digits.
+	k, n := len(digits), len(digits)+exp
+	var out string
+	switch {
+	case k <= n && n <= 21:
+		out = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		out = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		out = "0." + strings.Repeat("0", -n) + digits
+	default:
+		out = digits[:1]
+		if k > 1 {
+			out += "." + digits[1:]
+		}
+		if n-1 >= 0 {
+			out += "e+" + strconv.Itoa(n-1)
+		} else {
+			out += "e" + strconv.Itoa(n-1)
+		}
+	}
+	if neg {
+		out = "-" + out
+	}
+	return json.Number(out), nil
+}
+
+// decodeJSON decodes the single JSON value held in s, using json.Number
+// for numbers so that they aren't rounded through float64.
+func decodeJSON(s string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after top-level value")
+	}
+	return doc, nil
+}
+
+// canonicalJSON encodes v as compact JSON. The encoding/json package
+// already sorts map keys, so all that is left is to keep it from
+// escaping HTML characters and appending a newline.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Fingerprint coerces v with c and returns the result serialized as
+// canonical JSON, as done by CanonicalJSON, for hashing and change
+// detection. As the serialization happens after coercion, inputs that
+// only differ in key order or in how numbers are represented, such as
+// float64(1) and int(1) for a Float field, have identical fingerprints.
+// For other equivalences, such as lists whose order doesn't matter, c
+// may be wrapped with Canonicalized.
+//
+// The coerced value must be representable as JSON. Maps with non-string
+// keys, as returned by Map, are serialized with their keys formatted by
+// fmt.Sprint.
+func Fingerprint(c Checker, v interface{}) ([]byte, error) {
+	out, err := c.Coerce(v, nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := canonicalJSON(jsonValue(out))
+	if err != nil {
+		return nil, fmt.Errorf("cannot serialize coerced value: %v", err)
+	}
+	return data, nil
+}
+
+// CoerceJSON decodes data as a JSON document and coerces it with c.
+// Numbers are decoded as json.Number rather than float64, so that large
+// integers keep their precision for integer checkers. Errors reported
+// by c carry the path of the offending value within the document.
+func CoerceJSON(c Checker, data []byte) (interface{}, error) {
+	doc, err := decodeJSON(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode JSON: %v", err)
+	}
+	return c.Coerce(doc, nil)
+}
+
+// jsonValue returns v with any map[interface{}]interface{} within it
+// converted to a map[string]interface{}, so that it can be encoded
+// as JSON, and any json.Number in canonical form.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			m[fmt.Sprint(k)] = jsonValue(elem)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			m[k] = jsonValue(elem)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, elem := range v {
+			l[i] = jsonValue(elem)
+		}
+		return l
+	case json.Number:
+		if n, err := canonicalNumber(string(v)); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+// JSONPointer returns a Checker that accepts a string holding a JSON
+// Pointer as defined by RFC 6901, such as "/spec/containers/0", and
+// returns it unprocessed. A pointer must be empty, referring to the
+// whole document, or start with "/", and a "~" within it must be
+// escaped as "~0", with "~1" standing for "/".
+func JSONPointer() Checker {
+	return jsonPointerC{}
+}
+
+type jsonPointerC struct{}
+
+func (c jsonPointerC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	if err := validateJSONPointer(s); err != nil {
+		return nil, errorf(path, "invalid JSON pointer %q: %v", s, err)
+	}
+	return s, nil
+}
+
+func validateJSONPointer(s string) error {
+	if s != "" && s[0] != '/' {
+		return fmt.Errorf(`must be empty or start with "/"`)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '~' && (i+1 == len(s) || (s[i+1] != '0' && s[i+1] != '1')) {
+			return fmt.Errorf(`"~" must be escaped as "~0"`)
+		}
+	}
+	return nil
+}
+
+// JSONPatch returns a Checker that accepts a list of JSON Patch
+// operations as defined by RFC 6902, such as
+// {"op": "add", "path": "/a", "value": 1}, and returns the patch with
+// each operation as a map[string]interface{} holding only the members
+// relevant to it. Every operation must have a valid "op" and a "path"
+// holding a JSON pointer, along with a "value" for the add, replace and
+// test operations and a "from" pointer for the move and copy ones.
+//
+// The coerced output value has type []interface{}.
+func JSONPatch() Checker {
+	return jsonPatchC{}
+}
+
+type jsonPatchC struct{}
+
+// jsonPatchOps holds the members required by every JSON Patch
+// operation besides "op" and "path".
+var jsonPatchOps = map[string][]string{
+	"add":     {"value"},
+	"remove":  nil,
+	"replace": {"value"},
+	"move":    {"from"},
+	"copy":    {"from"},
+	"test":    {"value"},
+}
+
+func (c jsonPatchC) Coerce(v interface{}, path []string) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, CoerceError{Expected: "list", Got: v, Path: path}
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		op, err := coerceJSONPatchOp(rv.Index(i).Interface(), elemPath(path, i))
+		if err != nil {
+			return nil, err
+		}
+		out[i] = op
+	}
+	return out, nil
+}
+
+func coerceJSONPatchOp(v interface{}, path []string) (map[string]interface{}, error) {
+	m, ok := asStringMap(v)
+	if !ok {
+		return nil, CoerceError{Expected: "map", Got: v, Path: path}
+	}
+	name, err := String().Coerce(m["op"], append(path[:len(path):len(path)], ".", "op"))
+	if err != nil {
+		return nil, err
+	}
+	required, ok := jsonPatchOps[name.(string)]
+	if !ok {
+		return nil, errorf(append(path[:len(path):len(path)], ".", "op"), "unknown operation %q", name)
+	}
+	out := map[string]interface{}{"op": name}
+	for _, k := range append([]string{"path"}, required...) {
+		value, ok := m[k]
+		if !ok {
+			return nil, errorf(path, "operation %q is missing %q", name, k)
+		}
+		if k == "path" || k == "from" {
+			kpath := append(path[:len(path):len(path)], ".", k)
+			pointer, err := String().Coerce(value, kpath)
+			if err != nil {
+				return nil, err
+			}
+			if err := validateJSONPointer(pointer.(string)); err != nil {
+				return nil, errorf(kpath, "invalid JSON pointer %q: %v", pointer, err)
+			}
+		}
+		out[k] = value
+	}
+	return out, nil
+}
+
+// MergePatch returns a Checker that accepts a JSON Merge Patch as
+// defined by RFC 7386, in which members set to nil are removed from the
+// target and others are merged into it, recursively. The patch must be
+// a map with string keys, as must any map within it, and is returned
+// with every map converted to a map[string]interface{}.
+func MergePatch() Checker {
+	return mergePatchC{}
+}
+
+type mergePatchC struct{}
+
+func (c mergePatchC) Coerce(v interface{}, path []string) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, CoerceError{Expected: "map", Got: v, Path: path}
+	}
+	return coerceMergePatch(rv, path)
+}
+
+func coerceMergePatch(rv reflect.Value, path []string) (interface{}, error) {
+	if !hasStrictStringKeys(rv) {
+		return nil, CoerceError{Expected: "map[string]", Got: rv.Interface(), Path: path}
+	}
+	out := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		ks := keyString(k)
+		value := rv.MapIndex(k).Interface()
+		if vv := reflect.ValueOf(value); vv.Kind() == reflect.Map {
+			newv, err := coerceMergePatch(vv, append(path[:len(path):len(path)], ".", ks))
+			if err != nil {
+				return nil, err
+			}
+			value = newv
+		}
+		out[ks] = value
+	}
+	return out, nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"encoding/json"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type jsonSuite struct{}
+
+var _ = gc.Suite(&jsonSuite{})
+
+func (s *jsonSuite) TestCanonicalJSON(c *gc.C) {
+	sch := schema.CanonicalJSON()
+
+	out, err := sch.Coerce(`{
+		"b": [3, 2, {"z": 1, "a": 1.50}],
+		"a": "<&>"
+	}`, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, `{"a":"<&>","b":[3,2,{"a":1.5,"z":1}]}`)
+
+	// Canonicalization is stable.
+	again, err := sch.Coerce(out, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(again, gc.Equals, out)
+
+	out, err = sch.Coerce(`12345678901234567890`, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, `12345678901234567890`)
+
+	// Equivalent numbers are written identically, without rounding.
+	numbers := []struct {
+		in, out string
+	}{
+		{"1", "1"},
+		{"1.0", "1"},
+		{"1e0", "1"},
+		{"10E-1", "1"},
+		{"-0.0", "0"},
+		{"-1.250e2", "-125"},
+		{"0.000001", "0.000001"},
+		{"1e-7", "1e-7"},
+		{"123.456e-10", "1.23456e-8"},
+		{"1e21", "1e+21"},
+		{"1e20", "100000000000000000000"},
+		{"12345678901234567890123", "1.2345678901234567890123e+22"},
+		{"0.1000000000000000000001", "0.1000000000000000000001"},
+	}
+	for i, test := range numbers {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	out, err = sch.Coerce(`-1e99999999999`, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: invalid JSON: number -1e99999999999 out of range`)
+
+	out, err = sch.Coerce(`{"a":`, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: invalid JSON: unexpected EOF`)
+
+	out, err = sch.Coerce(`{} {}`, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: invalid JSON: unexpected data after top-level value`)
+
+	out, err = sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected string, got int\(42\)`)
+}
+
+func (s *jsonSuite) TestFingerprint(c *gc.C) {
+	sch := schema.FieldMap(schema.Fields{
+		"name":   schema.String(),
+		"weight": schema.Float(),
+		"labels": schema.Map(schema.Int(), schema.String()),
+		"tags":   schema.List(schema.String()),
+	}, schema.Defaults{
+		"tags": []interface{}{},
+	})
+
+	a, err := schema.Fingerprint(sch, map[string]interface{}{
+		"name":   "app",
+		"weight": 1.0,
+		"labels": map[interface{}]interface{}{2: "b", 1: "a"},
+		"extra":  true,
+	})
+	c.Assert(err, gc.IsNil)
+	c.Check(string(a), gc.Equals, `{"labels":{"1":"a","2":"b"},"name":"app","tags":[],"weight":1}`)
+
+	b, err := schema.Fingerprint(sch, map[interface{}]interface{}{
+		"labels": map[int]string{1: "a", 2: "b"},
+		"weight": int8(1),
+		"name":   "app",
+		"tags":   []string{},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Check(b, gc.DeepEquals, a)
+
+	_, err = schema.Fingerprint(sch, map[string]interface{}{"name": 1})
+	c.Check(err, gc.ErrorMatches, `.*: expected .*, got .*`)
+}
+
+func (s *jsonSuite) TestCoerceJSON(c *gc.C) {
+	sch := schema.FieldMap(schema.Fields{
+		"id":    schema.Int(),
+		"name":  schema.String(),
+		"ports": schema.List(schema.Port()),
+	}, nil)
+
+	out, err := schema.CoerceJSON(sch, []byte(`{"id": 9007199254740993, "name": "web", "ports": [80, "443"]}`))
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{
+		"id":    int64(9007199254740993),
+		"name":  "web",
+		"ports": []interface{}{80, 443},
+	})
+
+	_, err = schema.CoerceJSON(sch, []byte(`{"id": 1, "name": "web", "ports": [80, 0]}`))
+	c.Assert(err, gc.ErrorMatches, `ports\[1\]: port 0 out of range \[1, 65535\]`)
+
+	_, err = schema.CoerceJSON(sch, []byte(`{"id": 1,`))
+	c.Assert(err, gc.ErrorMatches, `cannot decode JSON: unexpected EOF`)
+
+	_, err = schema.CoerceJSON(sch, []byte(`{} {}`))
+	c.Assert(err, gc.ErrorMatches, `cannot decode JSON: unexpected data after top-level value`)
+}
+
+func (s *jsonSuite) TestJSONPointer(c *gc.C) {
+	sch := schema.JSONPointer()
+
+	for _, ptr := range []string{"", "/", "/a/b/0", "/a~1b", "/m~0n", "/ "} {
+		out, err := sch.Coerce(ptr, aPath)
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.Equals, ptr)
+	}
+
+	out, err := sch.Coerce("a/b", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: invalid JSON pointer "a/b": must be empty or start with "/"`)
+
+	out, err = sch.Coerce("/a~2", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: invalid JSON pointer "/a~2": "~" must be escaped as "~0"`)
+
+	out, err = sch.Coerce("/a~", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: invalid JSON pointer "/a~": "~" must be escaped as "~0"`)
+
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected string, got nothing`)
+}
+
+func (s *jsonSuite) TestJSONPatch(c *gc.C) {
+	sch := schema.JSONPatch()
+
+	var patch interface{}
+	err := json.Unmarshal([]byte(`[
+		{"op": "add", "path": "/a/-", "value": {"b": 1}},
+		{"op": "remove", "path": "/c", "value": "ignored"},
+		{"op": "replace", "path": "", "value": null},
+		{"op": "move", "from": "/d", "path": "/e"},
+		{"op": "copy", "from": "/d", "path": "/f", "comment": "x"},
+		{"op": "test", "path": "/g~1h", "value": [1, 2]}
+	]`), &patch)
+	c.Assert(err, gc.IsNil)
+	out, err := sch.Coerce(patch, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []interface{}{
+		map[string]interface{}{"op": "add", "path": "/a/-", "value": map[string]interface{}{"b": float64(1)}},
+		map[string]interface{}{"op": "remove", "path": "/c"},
+		map[string]interface{}{"op": "replace", "path": "", "value": nil},
+		map[string]interface{}{"op": "move", "from": "/d", "path": "/e"},
+		map[string]interface{}{"op": "copy", "from": "/d", "path": "/f"},
+		map[string]interface{}{"op": "test", "path": "/g~1h", "value": []interface{}{float64(1), float64(2)}},
+	})
+}
+
+func (s *jsonSuite) TestJSONPatchErrors(c *gc.C) {
+	sch := schema.JSONPatch()
+
+	tests := []struct {
+		patch string
+		err   string
+	}{{
+		patch: `[{"op": "add", "path": "/a"}]`,
+		err:   `<path>\[0\]: operation "add" is missing "value"`,
+	}, {
+		patch: `[{"op": "remove", "path": "/a"}, {"op": "move", "path": "/a"}]`,
+		err:   `<path>\[1\]: operation "move" is missing "from"`,
+	}, {
+		patch: `[{"op": "remove"}]`,
+		err:   `<path>\[0\]: operation "remove" is missing "path"`,
+	}, {
+		patch: `[{"op": "delete", "path": "/a"}]`,
+		err:   `<path>\[0\]\.op: unknown operation "delete"`,
+	}, {
+		patch: `[{"path": "/a"}]`,
+		err:   `<path>\[0\]\.op: expected string, got nothing`,
+	}, {
+		patch: `[{"op": "copy", "from": "a", "path": "/b"}]`,
+		err:   `<path>\[0\]\.from: invalid JSON pointer "a": must be empty or start with "/"`,
+	}, {
+		patch: `[{"op": "remove", "path": 1}]`,
+		err:   `<path>\[0\]\.path: expected string, got float64\(1\)`,
+	}, {
+		patch: `["remove"]`,
+		err:   `<path>\[0\]: expected map, got string\("remove"\)`,
+	}, {
+		patch: `{"op": "remove"}`,
+		err:   `<path>: expected list, got map\[string\]interface {}\(.*\)`,
+	}}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.patch)
+		var patch interface{}
+		err := json.Unmarshal([]byte(test.patch), &patch)
+		c.Assert(err, gc.IsNil)
+		_, err = sch.Coerce(patch, aPath)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *jsonSuite) TestMergePatch(c *gc.C) {
+	sch := schema.MergePatch()
+
+	out, err := sch.Coerce(map[interface{}]interface{}{
+		"a": nil,
+		"b": map[interface{}]interface{}{"c": 1},
+		"d": []interface{}{1},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{
+		"a": nil,
+		"b": map[string]interface{}{"c": 1},
+		"d": []interface{}{1},
+	})
+
+	_, err = sch.Coerce(map[string]interface{}{
+		"b": map[interface{}]interface{}{1: "x"},
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.b: expected map\[string\], got map\[interface {}\]interface {}\(.*\)`)
+
+	_, err = sch.Coerce([]interface{}{}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected map, got \[\]interface {}\(.*\)`)
+}
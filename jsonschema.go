@@ -0,0 +1,231 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"fmt"
+	"math"
+)
+
+// ToJSONSchema returns a JSON Schema (draft 2020-12) document
+// describing the values accepted by c, for publishing machine-readable
+// schemas derived from the checkers used for coercion. The document is
+// returned as a map ready to be encoded with encoding/json.
+//
+// Only checkers whose behaviour JSON Schema can express are supported:
+// Any, FlagPresent, String, NonEmptyString, StringLen, Match, Enum,
+// Bool, Int, Uint, Float, NumberRange, IntRange, Min, Max, Const,
+// Nullable, OneOf, AllOf, List, ListLen, Tuple, StringMap, Map with
+// String keys, FieldMap, WithDefault, and Constrained with Dependency
+// constraints using OnPresence. An error naming the path of the
+// offending checker is returned for anything else, rather than
+// producing a schema that accepts more than c does.
+//
+// Fields of a FieldMap without a default are listed as required, except
+// for fields gated by FeatureGated, which are optional and allowed even
+// when their feature is disabled. Deprecated fields are marked as such.
+// FieldMaps using CaseInsensitiveKeys are not supported.
+//
+// JSON Schema validates documents before coercion, so it can't account
+// for conversions performed by checkers, such as the parsing of
+// numbers held in strings.
+func ToJSONSchema(c Checker) (map[string]interface{}, error) {
+	s, err := jsonSchema(c, nil)
+	if err != nil {
+		return nil, err
+	}
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	return s, nil
+}
+
+func jsonSchema(c Checker, path []string) (map[string]interface{}, error) {
+	switch c := c.(type) {
+	case anyC, flagPresentC:
+		return map[string]interface{}{}, nil
+	case stringC:
+		return map[string]interface{}{"type": "string"}, nil
+	case nonEmptyStringC:
+		return map[string]interface{}{"type": "string", "minLength": 1}, nil
+	case stringLenC:
+		s := map[string]interface{}{"type": "string", "minLength": c.min}
+		if c.max >= 0 {
+			s["maxLength"] = c.max
+		}
+		return s, nil
+	case matchC:
+		return map[string]interface{}{"type": "string", "pattern": c.re.String()}, nil
+	case enumC:
+		if c.fold {
+			return nil, fmt.Errorf("%scannot express case-insensitive enum in JSON Schema", pathAsPrefix(path))
+		}
+		return map[string]interface{}{"type": "string", "enum": c.values}, nil
+	case boolC:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case intC:
+		return map[string]interface{}{"type": "integer"}, nil
+	case uintC:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case floatC, numberC:
+		return map[string]interface{}{"type": "number"}, nil
+	case intRangeC:
+		return jsonSchema(c.rangeC, path)
+	case rangeC:
+		s, err := jsonSchema(c.checker, path)
+		if err != nil {
+			return nil, err
+		}
+		if !math.IsInf(c.min, -1) {
+			s["minimum"] = c.min
+		}
+		if !math.IsInf(c.max, 1) {
+			s["maximum"] = c.max
+		}
+		return s, nil
+	case constC:
+		return map[string]interface{}{"const": c.value}, nil
+	case nullableC:
+		s, err := jsonSchema(c.checker, path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"anyOf": []interface{}{s, map[string]interface{}{"type": "null"}}}, nil
+	case oneOfC:
+		options, err := jsonSchemas(c.options, path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"anyOf": options}, nil
+	case allOfC:
+		checkers, err := jsonSchemas(c.checkers, path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"allOf": checkers}, nil
+	case listC:
+		return jsonArraySchema(c.elem, path)
+	case listLenC:
+		s, err := jsonArraySchema(c.elem, path)
+		if err != nil {
+			return nil, err
+		}
+		s["minItems"] = c.min
+		if c.max >= 0 {
+			s["maxItems"] = c.max
+		}
+		return s, nil
+	case tupleC:
+		items, err := jsonSchemas(c.checkers, path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"type":        "array",
+			"prefixItems": items,
+			"items":       false,
+			"minItems":    len(items),
+		}, nil
+	case mapC:
+		if _, ok := c.key.(stringC); !ok {
+			return nil, fmt.Errorf("%scannot express map keys other than strings in JSON Schema", pathAsPrefix(path))
+		}
+		return jsonObjectSchema(c.value, path)
+	case stringMapC:
+		return jsonObjectSchema(c.value, path)
+	case fieldMapC:
+		return jsonFieldMapSchema(c, path)
+	case withDefaultC:
+		s, err := jsonSchema(c.checker, path)
+		if err != nil {
+			return nil, err
+		}
+		s["default"] = c.dflt
+		return s, nil
+	case constrainedC:
+		s, err := jsonSchema(c.checker, path)
+		if err != nil {
+			return nil, err
+		}
+		deps := make(map[string]interface{})
+		for _, constraint := range c.constraints {
+			d, ok := constraint.(Dependency)
+			if !ok || !d.OnPresence {
+				return nil, fmt.Errorf("%scannot express constraint %#v in JSON Schema", pathAsPrefix(path), constraint)
+			}
+			fields, _ := deps[d.On].([]string)
+			deps[d.On] = append(fields, d.Field)
+		}
+		if len(deps) > 0 {
+			s["dependentRequired"] = deps
+		}
+		return s, nil
+	}
+	label, _ := describeChecker(c)
+	return nil, fmt.Errorf("%scannot express %s in JSON Schema", pathAsPrefix(path), label)
+}
+
+func jsonSchemas(checkers []Checker, path []string) ([]interface{}, error) {
+	schemas := make([]interface{}, len(checkers))
+	for i, c := range checkers {
+		s, err := jsonSchema(c, path)
+		if err != nil {
+			return nil, err
+		}
+		schemas[i] = s
+	}
+	return schemas, nil
+}
+
+func jsonArraySchema(elem Checker, path []string) (map[string]interface{}, error) {
+	items, err := jsonSchema(elem, append(path[:len(path):len(path)], "[", "*", "]"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"type": "array", "items": items}, nil
+}
+
+func jsonObjectSchema(value Checker, path []string) (map[string]interface{}, error) {
+	values, err := jsonSchema(value, append(path[:len(path):len(path)], ".", "*"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+}
+
+func jsonFieldMapSchema(c fieldMapC, path []string) (map[string]interface{}, error) {
+	if c.foldedNames != nil {
+		return nil, fmt.Errorf("%scannot express case-insensitive keys in JSON Schema", pathAsPrefix(path))
+	}
+	properties := make(map[string]interface{}, len(c.fields))
+	required := []string{}
+	for _, name := range c.fieldNames(true) {
+		s, err := jsonSchema(c.fields[name], append(path[:len(path):len(path)], ".", name))
+		if err != nil {
+			return nil, err
+		}
+		_, gated := c.gates[name]
+		if dflt, ok := c.defaults[name]; ok {
+			if dflt != Omit {
+				s["default"] = dflt
+			}
+		} else if _, ok := c.fields[name].(flagPresentC); !ok && !gated {
+			required = append(required, name)
+		}
+		if _, ok := c.deprecated[name]; ok {
+			s["deprecated"] = true
+		}
+		properties[name] = s
+	}
+	s := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	if c.strict {
+		s["additionalProperties"] = false
+	}
+	if c.preserveUnknown {
+		s["additionalProperties"] = true
+	}
+	return s, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"encoding/json"
+	"math"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type jsonSchemaSuite struct{}
+
+var _ = gc.Suite(&jsonSchemaSuite{})
+
+// checkJSONSchema checks that the JSON Schema of c encodes to the same
+// document as the expected JSON.
+func checkJSONSchema(c *gc.C, checker schema.Checker, expected string) {
+	s, err := schema.ToJSONSchema(checker)
+	c.Assert(err, gc.IsNil)
+	data, err := json.Marshal(s)
+	c.Assert(err, gc.IsNil)
+	var got, want interface{}
+	c.Assert(json.Unmarshal(data, &got), gc.IsNil)
+	c.Assert(json.Unmarshal([]byte(expected), &want), gc.IsNil)
+	c.Check(got, gc.DeepEquals, want, gc.Commentf("got %s", data))
+}
+
+func (s *jsonSchemaSuite) TestToJSONSchema(c *gc.C) {
+	sch := schema.Constrained(schema.StrictFieldMap(schema.Fields{
+		"name":    schema.NonEmptyString("name"),
+		"mode":    schema.Enum("fast", "safe"),
+		"port":    schema.IntRange(1, 65535),
+		"ratio":   schema.Nullable(schema.NumberRange(0, 1)),
+		"tags":    schema.ListLen(schema.StringLen(1, -1), 0, 10),
+		"labels":  schema.StringMap(schema.String()),
+		"kind":    schema.OneOf(schema.Const("a"), schema.Bool()),
+		"debug":   schema.FlagPresent(),
+		"cert":    schema.String(),
+		"key":     schema.String(),
+		"servers": schema.List(schema.FieldMap(schema.Fields{"host": schema.Match("^[a-z]+$")}, nil)),
+		"pair":    schema.Tuple(schema.String(), schema.Uint()),
+	}, schema.Defaults{
+		"mode":   "safe",
+		"port":   8080,
+		"ratio":  schema.Omit,
+		"tags":   schema.Omit,
+		"labels": schema.Omit,
+		"kind":   schema.Omit,
+		"cert":   schema.Omit,
+		"key":    schema.Omit,
+		"pair":   schema.Omit,
+	}), schema.Dependency{Field: "cert", On: "key", OnPresence: true})
+
+	checkJSONSchema(c, sch, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["name", "servers"],
+		"dependentRequired": {"key": ["cert"]},
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"mode": {"type": "string", "enum": ["fast", "safe"], "default": "safe"},
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535, "default": 8080},
+			"ratio": {"anyOf": [{"type": "number", "minimum": 0, "maximum": 1}, {"type": "null"}]},
+			"tags": {"type": "array", "minItems": 0, "maxItems": 10, "items": {"type": "string", "minLength": 1}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"kind": {"anyOf": [{"const": "a"}, {"type": "boolean"}]},
+			"debug": {},
+			"cert": {"type": "string"},
+			"key": {"type": "string"},
+			"servers": {"type": "array", "items": {
+				"type": "object",
+				"required": ["host"],
+				"properties": {"host": {"type": "string", "pattern": "^[a-z]+$"}}
+			}},
+			"pair": {"type": "array", "prefixItems": [{"type": "string"}, {"type": "integer", "minimum": 0}], "items": false, "minItems": 2}
+		}
+	}`)
+}
+
+func (s *jsonSchemaSuite) TestToJSONSchemaScalars(c *gc.C) {
+	checkJSONSchema(c, schema.Min(1), `{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "number", "minimum": 1}`)
+	checkJSONSchema(c, schema.NumberRange(math.Inf(-1), 5), `{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "number", "maximum": 5}`)
+	checkJSONSchema(c, schema.WithDefault(schema.Float(), 0.5), `{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "number", "default": 0.5}`)
+	checkJSONSchema(c, schema.AllOf(schema.Any(), schema.Map(schema.String(), schema.Int())), `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"allOf": [{}, {"type": "object", "additionalProperties": {"type": "integer"}}]
+	}`)
+}
+
+func (s *jsonSchemaSuite) TestToJSONSchemaFieldMapVariants(c *gc.C) {
+	fields := schema.Fields{"a": schema.Int(), "b": schema.Int(), "c": schema.Int()}
+	sch := schema.Deprecated(schema.FeatureGated(schema.FieldMap(fields, schema.Defaults{"c": 1}),
+		map[string]schema.FeatureRule{"b": {Feature: "beta", Required: true}}),
+		map[string]string{"c": "use a instead"})
+	checkJSONSchema(c, sch, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"a": {"type": "integer"},
+			"b": {"type": "integer"},
+			"c": {"type": "integer", "default": 1, "deprecated": true}
+		},
+		"required": ["a"]
+	}`)
+
+	checkJSONSchema(c, schema.PreserveUnknown(schema.FieldMap(schema.Fields{"a": schema.Int()}, nil)), `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {"a": {"type": "integer"}},
+		"required": ["a"],
+		"additionalProperties": true
+	}`)
+}
+
+func (s *jsonSchemaSuite) TestToJSONSchemaErrors(c *gc.C) {
+	tests := []struct {
+		checker schema.Checker
+		err     string
+	}{{
+		checker: schema.UUID(),
+		err:     `cannot express uuid in JSON Schema`,
+	}, {
+		checker: schema.FieldMap(schema.Fields{"a": schema.List(schema.TimeDuration())}, nil),
+		err:     `a\[\*\]: cannot express duration in JSON Schema`,
+	}, {
+		checker: schema.StringMap(schema.EnumFold("A")),
+		err:     `\*: cannot express case-insensitive enum in JSON Schema`,
+	}, {
+		checker: schema.Map(schema.Int(), schema.Int()),
+		err:     `cannot express map keys other than strings in JSON Schema`,
+	}, {
+		checker: schema.Constrained(schema.FieldMap(schema.Fields{"a": schema.Int(), "b": schema.Int()}, nil),
+			schema.FieldRelation{"a", schema.LessThan, "b"}),
+		err: `cannot express constraint schema.FieldRelation{A:"a", Op:"<", B:"b"} in JSON Schema`,
+	}, {
+		checker: schema.List(schema.CaseInsensitiveKeys(schema.FieldMap(schema.Fields{"a": schema.Int()}, nil))),
+		err:     `\[\*\]: cannot express case-insensitive keys in JSON Schema`,
+	}}
+	for i, test := range tests {
+		c.Logf("test %d", i)
+		s, err := schema.ToJSONSchema(test.checker)
+		c.Check(s, gc.IsNil)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -8,9 +8,9 @@ import (
 	"strconv"
 )
 
-// List returns a Checker that accepts a slice value with values
-// that are processed with the elem checker.  If any element of the
-// provided slice value fails to be processed, processing will stop
+// List returns a Checker that accepts a slice or array value with
+// values that are processed with the elem checker.  If any element of
+// the provided value fails to be processed, processing will stop
 // and return with the obtained error.
 //
 // The coerced output value has type []interface{}.
@@ -23,18 +23,27 @@ type listC struct {
 }
 
 func (c listC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c listC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
 	rv := reflect.ValueOf(v)
-	if rv.Kind() != reflect.Slice {
-		return nil, error_{"list", v, path}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, CoerceError{Expected: "list", Got: v, Path: path}
 	}
 
-	path = append(path, "[", "?", "]")
+	epath := append(path, "[", "?", "]")
 
 	l := rv.Len()
 	out := make([]interface{}, 0, l)
 	for i := 0; i != l; i++ {
-		path[len(path)-2] = strconv.Itoa(i)
-		elem, err := c.elem.Coerce(rv.Index(i).Interface(), path)
+		if st.accumulating() {
+			// Errors keep hold of their path.
+			epath = elemPath(path, i)
+		} else {
+			epath[len(epath)-2] = strconv.Itoa(i)
+		}
+		elem, err := coerceField(c.elem, rv.Index(i).Interface(), epath, st)
 		if err != nil {
 			return nil, err
 		}
@@ -42,3 +51,175 @@ func (c listC) Coerce(v interface{}, path []string) (interface{}, error) {
 	}
 	return out, nil
 }
+
+// ListLen returns a Checker that acts as the one returned by List, but
+// additionally requires the list to have between min and max elements,
+// inclusive. A negative max leaves the length unbounded above.
+func ListLen(elem Checker, min, max int) Checker {
+	return listLenC{elem, min, max}
+}
+
+type listLenC struct {
+	elem     Checker
+	min, max int
+}
+
+func (c listLenC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c listLenC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
+	out, err := listC{c.elem}.coerce(v, path, st)
+	if err != nil {
+		return nil, err
+	}
+	n := len(out.([]interface{}))
+	if c.max < 0 && n < c.min {
+		return nil, errorf(path, "list length %d is less than the minimum %d", n, c.min)
+	}
+	if c.max >= 0 && (n < c.min || n > c.max) {
+		return nil, errorf(path, "list length %d not in range [%d, %d]", n, c.min, c.max)
+	}
+	return out, nil
+}
+
+// Tuple returns a Checker that accepts a slice or array value with
+// exactly as many elements as there are checkers, and processes each
+// element with the checker in the same position, as for a
+// [name, port, weight] record.
+//
+// The coerced output value has type []interface{}.
+func Tuple(checkers ...Checker) Checker {
+	return tupleC{checkers}
+}
+
+type tupleC struct {
+	checkers []Checker
+}
+
+func (c tupleC) Coerce(v interface{}, path []string) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, CoerceError{Expected: "list", Got: v, Path: path}
+	}
+	if rv.Len() != len(c.checkers) {
+		return nil, errorf(path, "expected list of %d elements, got %d", len(c.checkers), rv.Len())
+	}
+	out := make([]interface{}, len(c.checkers))
+	for i, checker := range c.checkers {
+		elem, err := checker.Coerce(rv.Index(i).Interface(), elemPath(path, i))
+		if err != nil {
+			return nil, err
+		}
+		out[i] = elem
+	}
+	return out, nil
+}
+
+// ListOrScalar returns a Checker that acts as the one returned by List
+// when the value is a list, and otherwise processes the value with the
+// elem checker as if it were the only element of a list, as for YAML
+// fields that accept either a single string or a list of strings.
+// Errors therefore always carry the index of the element, as in
+// "[0]".
+//
+// The coerced output value has type []interface{}.
+func ListOrScalar(elem Checker) Checker {
+	return listOrScalarC{elem}
+}
+
+type listOrScalarC struct {
+	elem Checker
+}
+
+func (c listOrScalarC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if kind := reflect.ValueOf(v).Kind(); kind == reflect.Slice || kind == reflect.Array {
+		return List(c.elem).Coerce(v, path)
+	}
+	elem, err := c.elem.Coerce(v, append(path[:len(path):len(path)], "[", "0", "]"))
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{elem}, nil
+}
+
+// DedupeStrings returns a Checker that accepts a list of strings and
+// returns them as a []string with any repeated values removed, keeping
+// the first occurrence of each in place. It suits lists such as tags,
+// where repeats are harmless.
+func DedupeStrings() Checker {
+	return dedupeStringsC{}
+}
+
+type dedupeStringsC struct{}
+
+func (c dedupeStringsC) Coerce(v interface{}, path []string) (interface{}, error) {
+	elems, err := List(String()).Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(elems.([]interface{})))
+	seen := make(map[string]bool)
+	for _, elem := range elems.([]interface{}) {
+		s := elem.(string)
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// KeyValue holds a single key and its associated value.
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedMap returns a Checker that accepts a list of single-entry maps,
+// such as the YAML document "[{b: 1}, {a: 2}]", as a way of expressing
+// a map whose keys are ordered. Every value is processed with the value
+// checker, and keys must be unique across the list.
+//
+// The coerced output value has type []KeyValue, in input order.
+func OrderedMap(value Checker) Checker {
+	return orderedMapC{value}
+}
+
+type orderedMapC struct {
+	value Checker
+}
+
+func (c orderedMapC) Coerce(v interface{}, path []string) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, CoerceError{Expected: "list", Got: v, Path: path}
+	}
+
+	path = append(path, "[", "?", "]", ".", "?")
+
+	l := rv.Len()
+	out := make([]KeyValue, 0, l)
+	seen := make(map[string]bool, l)
+	for i := 0; i != l; i++ {
+		path[len(path)-4] = strconv.Itoa(i)
+		elem := rv.Index(i).Interface()
+		erv := reflect.ValueOf(elem)
+		if erv.Kind() != reflect.Map || erv.Len() != 1 || !hasStrictStringKeys(erv) {
+			return nil, CoerceError{Expected: "single-entry map", Got: elem, Path: path[:len(path)-2]}
+		}
+		k := erv.MapKeys()[0]
+		key := keyString(k)
+		if seen[key] {
+			return nil, errorf(path[:len(path)-2], "duplicate key %q", key)
+		}
+		seen[key] = true
+		path[len(path)-1] = key
+		newv, err := c.value.Coerce(erv.MapIndex(k).Interface(), path)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, KeyValue{key, newv})
+	}
+	return out, nil
+}
@@ -6,6 +6,7 @@ package schema
 import (
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 // Map returns a Checker that accepts a map value. Every key and value
@@ -24,9 +25,13 @@ type mapC struct {
 }
 
 func (c mapC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c mapC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Map {
-		return nil, error_{"map", v, path}
+		return nil, CoerceError{Expected: "map", Got: v, Path: path}
 	}
 
 	vpath := append(path, ".", "?")
@@ -34,14 +39,22 @@ func (c mapC) Coerce(v interface{}, path []string) (interface{}, error) {
 	l := rv.Len()
 	out := make(map[interface{}]interface{}, l)
 	keys := rv.MapKeys()
+	if st.accumulating() {
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface()) })
+	}
 	for i := 0; i != l; i++ {
 		k := keys[i]
-		newk, err := c.key.Coerce(k.Interface(), path)
+		newk, err := coerceField(c.key, k.Interface(), path, st)
 		if err != nil {
 			return nil, err
 		}
-		vpath[len(vpath)-1] = fmt.Sprint(k.Interface())
-		newv, err := c.value.Coerce(rv.MapIndex(k).Interface(), vpath)
+		if st.accumulating() {
+			// Errors keep hold of their path.
+			vpath = append(path[:len(path):len(path)], ".", fmt.Sprint(k.Interface()))
+		} else {
+			vpath[len(vpath)-1] = fmt.Sprint(k.Interface())
+		}
+		newv, err := coerceField(c.value, rv.MapIndex(k).Interface(), vpath, st)
 		if err != nil {
 			return nil, err
 		}
@@ -51,7 +64,8 @@ func (c mapC) Coerce(v interface{}, path []string) (interface{}, error) {
 }
 
 // StringMap returns a Checker that accepts a map value. Every key in
-// the map must be a string, and every value in the map are processed
+// the map must be a string, or an interface{} holding a string as
+// produced by some YAML decoders, and every value in the map are processed
 // with the provided checker. If any value fails to be coerced,
 // processing stops and returns with the underlying error.
 //
@@ -65,29 +79,69 @@ type stringMapC struct {
 }
 
 func (c stringMapC) Coerce(v interface{}, path []string) (interface{}, error) {
+	return c.coerce(v, path, nil)
+}
+
+func (c stringMapC) coerce(v interface{}, path []string, st *coerceState) (interface{}, error) {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Map {
-		return nil, error_{"map", v, path}
+		return nil, CoerceError{Expected: "map", Got: v, Path: path}
+	}
+	if !hasStrictStringKeys(rv) {
+		return nil, CoerceError{Expected: "map[string]", Got: v, Path: path}
 	}
 
 	vpath := append(path, ".", "?")
-	key := String()
 
 	l := rv.Len()
 	out := make(map[string]interface{}, l)
 	keys := rv.MapKeys()
+	if st.accumulating() {
+		sort.Slice(keys, func(i, j int) bool { return keyString(keys[i]) < keyString(keys[j]) })
+	}
 	for i := 0; i != l; i++ {
 		k := keys[i]
-		newk, err := key.Coerce(k.Interface(), path)
-		if err != nil {
-			return nil, err
+		ks := keyString(k)
+		if st.accumulating() {
+			// Errors keep hold of their path.
+			vpath = append(path[:len(path):len(path)], ".", ks)
+		} else {
+			vpath[len(vpath)-1] = ks
 		}
-		vpath[len(vpath)-1] = fmt.Sprint(k.Interface())
-		newv, err := c.value.Coerce(rv.MapIndex(k).Interface(), vpath)
+		newv, err := coerceField(c.value, rv.MapIndex(k).Interface(), vpath, st)
 		if err != nil {
 			return nil, err
 		}
-		out[newk.(string)] = newv
+		out[ks] = newv
 	}
 	return out, nil
 }
+
+// MapInvariant returns a Checker that acts as the one returned by
+// StringMap, but additionally calls invariant with the coerced map once
+// all its values have been processed. This allows checking constraints
+// over the map as a whole, such as weights that must sum to 100 or a
+// single entry marked as primary. An error returned by invariant is
+// reported along with the path of the map.
+//
+// The coerced output value has type map[string]interface{}.
+func MapInvariant(value Checker, invariant func(map[string]interface{}) error) Checker {
+	return mapInvariantC{value, invariant}
+}
+
+type mapInvariantC struct {
+	value     Checker
+	invariant func(map[string]interface{}) error
+}
+
+func (c mapInvariantC) Coerce(v interface{}, path []string) (interface{}, error) {
+	out, err := StringMap(c.value).Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	m := out.(map[string]interface{})
+	if err := c.invariant(m); err != nil {
+		return nil, errorf(path, "%v", err)
+	}
+	return m, nil
+}
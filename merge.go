@@ -0,0 +1,88 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Merged returns a Checker that accepts a list of maps, merges them
+// with MergeMaps and coerces the merged map with c. It is intended for
+// layered configuration, where a defaults document is overridden by
+// one or more user supplied documents.
+//
+// Errors reported by c refer to paths within the merged map, as the
+// individual layers no longer exist at that point.
+func Merged(c Checker) Checker {
+	return mergedC{c}
+}
+
+type mergedC struct {
+	checker Checker
+}
+
+func (c mergedC) Coerce(v interface{}, path []string) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, CoerceError{Expected: "list of maps", Got: v, Path: path}
+	}
+	lpath := append(path, "[", "?", "]")
+	layers := make([]map[string]interface{}, rv.Len())
+	for i := range layers {
+		elem := rv.Index(i).Interface()
+		m, ok := asStringMap(elem)
+		if !ok {
+			lpath[len(lpath)-2] = strconv.Itoa(i)
+			return nil, CoerceError{Expected: "map[string]", Got: elem, Path: lpath}
+		}
+		layers[i] = m
+	}
+	return c.checker.Coerce(MergeMaps(layers...), path)
+}
+
+// MergeMaps returns the deep merge of the provided maps. Maps are
+// applied in order, so values in later maps take precedence over
+// values in earlier ones. When both the existing and the overriding
+// value for a key are maps with string keys, they are merged
+// recursively. Any other value, including lists, replaces the
+// existing value wholesale: lists are never concatenated or merged
+// element by element. The input maps are not modified.
+func MergeMaps(maps ...map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for _, m := range maps {
+		mergeInto(out, m)
+	}
+	return out
+}
+
+func mergeInto(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok := asStringMap(v)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstMap, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dstMap = make(map[string]interface{}, len(srcMap))
+			dst[k] = dstMap
+		}
+		mergeInto(dstMap, srcMap)
+	}
+}
+
+// asStringMap returns v as a map[string]interface{} if it is a map
+// whose keys are all strings.
+func asStringMap(v interface{}) (map[string]interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || !hasStrictStringKeys(rv) {
+		return nil, false
+	}
+	m := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		m[reflect.ValueOf(k.Interface()).String()] = rv.MapIndex(k).Interface()
+	}
+	return m, true
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type mergeSuite struct{}
+
+var _ = gc.Suite(&mergeSuite{})
+
+func (s *mergeSuite) TestMergeMaps(c *gc.C) {
+	defaults := map[string]interface{}{
+		"name":  "default",
+		"ports": []interface{}{80, 443},
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": 5432,
+		},
+	}
+	override := map[string]interface{}{
+		"ports": []interface{}{8080},
+		"db": map[interface{}]interface{}{
+			"host": "db.example.com",
+		},
+	}
+	out := schema.MergeMaps(defaults, override)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{
+		"name":  "default",
+		"ports": []interface{}{8080},
+		"db": map[string]interface{}{
+			"host": "db.example.com",
+			"port": 5432,
+		},
+	})
+
+	// The inputs are left untouched.
+	c.Assert(defaults["db"], gc.DeepEquals, map[string]interface{}{
+		"host": "localhost",
+		"port": 5432,
+	})
+}
+
+func (s *mergeSuite) TestMergeMapsScalarReplacesMap(c *gc.C) {
+	out := schema.MergeMaps(
+		map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+		map[string]interface{}{"a": "flat"},
+	)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"a": "flat"})
+}
+
+func (s *mergeSuite) TestMerged(c *gc.C) {
+	sch := schema.Merged(schema.FieldMap(schema.Fields{
+		"name": schema.String(),
+		"size": schema.Int(),
+	}, nil))
+
+	out, err := sch.Coerce([]interface{}{
+		map[string]interface{}{"name": "a", "size": 1},
+		map[string]interface{}{"size": "2"},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"name": "a", "size": int64(2)})
+
+	out, err = sch.Coerce([]interface{}{
+		map[string]interface{}{"name": "a", "size": 1},
+		map[string]interface{}{"size": true},
+	}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>\.size: expected int, got bool\(true\)`)
+
+	out, err = sch.Coerce([]interface{}{"a"}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>\[0\]: expected map\[string\], got string\("a"\)`)
+
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected list of maps, got nothing`)
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MigrationRules describes how Migrate transforms a document from an
+// old format to a new one. Fields are named by dotted paths into
+// nested maps, as in "db.host".
+type MigrationRules struct {
+	// Moves maps the path of a field in the old format to its path
+	// in the new format, as in {"db.host": "database.host"}. All
+	// fields are removed before any is inserted, so fields may be
+	// swapped, and missing intermediate maps are created. No two
+	// fields may be moved to the same path.
+	Moves map[string]string
+
+	// Transforms maps the path of a field in the new format to a
+	// function converting its value, applied after all moves and
+	// only if the field is present.
+	Transforms map[string]func(interface{}) (interface{}, error)
+
+	// Strict causes top level fields of the migrated document that
+	// are dropped when coercing it to the new format to be reported
+	// as an error, so that old fields can't be silently lost.
+	Strict bool
+}
+
+// Migrate returns a function that migrates a document from the format
+// accepted by from to the format accepted by to, so that stored
+// configuration can be upgraded automatically. The document is first
+// coerced with from, then rewritten according to rules, and finally
+// coerced with to, whose result is returned. Both checkers must result
+// in a map[string]interface{}, as returned by FieldMap. The input
+// document is not modified. Errors from the checkers are wrapped, so
+// FieldPath still finds their path.
+//
+// Migrate panics if two fields of rules.Moves are moved to the same
+// path.
+func Migrate(from, to Checker, rules MigrationRules) func(map[string]interface{}) (map[string]interface{}, error) {
+	olds := make([]string, 0, len(rules.Moves))
+	for old := range rules.Moves {
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+	sources := make(map[string]string)
+	for _, old := range olds {
+		newKey := rules.Moves[old]
+		if other, ok := sources[newKey]; ok {
+			panic(fmt.Sprintf("Migrate got moves of both %q and %q to %q", other, old, newKey))
+		}
+		sources[newKey] = old
+	}
+	return func(doc map[string]interface{}) (map[string]interface{}, error) {
+		out, err := from.Coerce(doc, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid old document: %w", err)
+		}
+		m, ok := asStringMap(out)
+		if !ok {
+			return nil, CoerceError{Expected: "map[string]", Got: out, Path: nil}
+		}
+		// MergeMaps copies all nested maps, so the moves below
+		// don't affect the input document.
+		m = MergeMaps(m)
+
+		moved := make(map[string]interface{})
+		for _, old := range olds {
+			if v, ok := removeDotted(m, old); ok {
+				moved[rules.Moves[old]] = v
+			}
+		}
+		for _, old := range olds {
+			newKey := rules.Moves[old]
+			if v, ok := moved[newKey]; ok {
+				if err := setDotted(m, newKey, v); err != nil {
+					return nil, fmt.Errorf("cannot move %q to %q: %w", old, newKey, err)
+				}
+			}
+		}
+
+		paths := make([]string, 0, len(rules.Transforms))
+		for p := range rules.Transforms {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			v, ok := lookupDotted(m, p)
+			if !ok {
+				continue
+			}
+			v, err := rules.Transforms[p](v)
+			if err != nil {
+				return nil, fmt.Errorf("cannot transform %q: %w", p, err)
+			}
+			if err := setDotted(m, p, v); err != nil {
+				return nil, fmt.Errorf("cannot transform %q: %w", p, err)
+			}
+		}
+
+		out, err = to.Coerce(m, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migrated document: %w", err)
+		}
+		result, ok := out.(map[string]interface{})
+		if !ok {
+			return nil, CoerceError{Expected: "map[string]", Got: out, Path: nil}
+		}
+		if rules.Strict {
+			var unmapped []string
+			for k := range m {
+				if _, ok := result[k]; !ok {
+					unmapped = append(unmapped, k)
+				}
+			}
+			if len(unmapped) > 0 {
+				sort.Strings(unmapped)
+				return nil, fmt.Errorf("unmapped old fields %q", unmapped)
+			}
+		}
+		return result, nil
+	}
+}
+
+// lookupDotted returns the value at the dotted path p within m.
+func lookupDotted(m map[string]interface{}, p string) (interface{}, bool) {
+	keys := strings.Split(p, ".")
+	for _, k := range keys[:len(keys)-1] {
+		var ok bool
+		if m, ok = m[k].(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	v, ok := m[keys[len(keys)-1]]
+	return v, ok
+}
+
+// removeDotted removes the value at the dotted path p within m,
+// returning it.
+func removeDotted(m map[string]interface{}, p string) (interface{}, bool) {
+	v, ok := lookupDotted(m, p)
+	if !ok {
+		return nil, false
+	}
+	i := strings.LastIndex(p, ".")
+	if i >= 0 {
+		parent, _ := lookupDotted(m, p[:i])
+		m = parent.(map[string]interface{})
+	}
+	delete(m, p[i+1:])
+	return v, true
+}
+
+// setDotted sets the value at the dotted path p within m, creating
+// intermediate maps as needed.
+func setDotted(m map[string]interface{}, p string, v interface{}) error {
+	keys := strings.Split(p, ".")
+	for i, k := range keys[:len(keys)-1] {
+		switch next := m[k].(type) {
+		case map[string]interface{}:
+			m = next
+		case nil:
+			if _, ok := m[k]; ok {
+				return fmt.Errorf("%q is not a map", strings.Join(keys[:i+1], "."))
+			}
+			child := make(map[string]interface{})
+			m[k] = child
+			m = child
+		default:
+			return fmt.Errorf("%q is not a map", strings.Join(keys[:i+1], "."))
+		}
+	}
+	m[keys[len(keys)-1]] = v
+	return nil
+}
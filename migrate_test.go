@@ -0,0 +1,119 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"fmt"
+	"time"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type migrateSuite struct{}
+
+var _ = gc.Suite(&migrateSuite{})
+
+var (
+	oldFormat = schema.FieldMap(schema.Fields{
+		"db_host": schema.String(),
+		"db_port": schema.Int(),
+		"timeout": schema.Int(),
+		"debug":   schema.Bool(),
+	}, schema.Defaults{
+		"db_port": 5432,
+		"debug":   schema.Omit,
+	})
+	newFormat = schema.FieldMap(schema.Fields{
+		"database": schema.FieldMap(schema.Fields{
+			"host": schema.String(),
+			"port": schema.Int(),
+		}, nil),
+		"timeout": schema.TimeDuration(),
+	}, nil)
+)
+
+func (s *migrateSuite) TestMigrate(c *gc.C) {
+	migrate := schema.Migrate(oldFormat, newFormat, schema.MigrationRules{
+		Moves: map[string]string{
+			"db_host": "database.host",
+			"db_port": "database.port",
+		},
+		Transforms: map[string]func(interface{}) (interface{}, error){
+			"timeout": func(v interface{}) (interface{}, error) {
+				return fmt.Sprintf("%ds", v), nil
+			},
+		},
+	})
+	doc := map[string]interface{}{"db_host": "db.example.com", "timeout": 30, "debug": true}
+	out, err := migrate(doc)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{
+		"database": map[string]interface{}{
+			"host": "db.example.com",
+			"port": int64(5432),
+		},
+		"timeout": 30 * time.Second,
+	})
+	// The input document is untouched.
+	c.Assert(doc, gc.DeepEquals, map[string]interface{}{"db_host": "db.example.com", "timeout": 30, "debug": true})
+
+	_, err = migrate(map[string]interface{}{"timeout": 30})
+	c.Assert(err, gc.ErrorMatches, `invalid old document: db_host: expected string, got nothing`)
+}
+
+func (s *migrateSuite) TestMigrateStrict(c *gc.C) {
+	migrate := schema.Migrate(oldFormat, newFormat, schema.MigrationRules{
+		Moves: map[string]string{
+			"db_host": "database.host",
+			"db_port": "database.port",
+		},
+		Strict: true,
+	})
+	_, err := migrate(map[string]interface{}{"db_host": "h", "timeout": 1, "debug": true})
+	c.Assert(err, gc.ErrorMatches, `unmapped old fields \["debug"\]`)
+	out, err := migrate(map[string]interface{}{"db_host": "h", "timeout": 1})
+	c.Assert(err, gc.IsNil)
+	c.Assert(out["database"], gc.DeepEquals, map[string]interface{}{"host": "h", "port": int64(5432)})
+}
+
+func (s *migrateSuite) TestMigrateErrors(c *gc.C) {
+	migrate := schema.Migrate(oldFormat, newFormat, schema.MigrationRules{
+		Moves: map[string]string{"db_host": "timeout.host"},
+	})
+	_, err := migrate(map[string]interface{}{"db_host": "h", "timeout": 1})
+	c.Assert(err, gc.ErrorMatches, `cannot move "db_host" to "timeout.host": "timeout" is not a map`)
+
+	migrate = schema.Migrate(oldFormat, newFormat, schema.MigrationRules{
+		Transforms: map[string]func(interface{}) (interface{}, error){
+			"timeout": func(v interface{}) (interface{}, error) {
+				return nil, fmt.Errorf("bad timeout %v", v)
+			},
+		},
+	})
+	_, err = migrate(map[string]interface{}{"db_host": "h", "timeout": 1})
+	c.Assert(err, gc.ErrorMatches, `cannot transform "timeout": bad timeout 1`)
+
+	migrate = schema.Migrate(oldFormat, newFormat, schema.MigrationRules{})
+	_, err = migrate(map[string]interface{}{"db_host": "h", "timeout": 1})
+	c.Assert(err, gc.ErrorMatches, `invalid migrated document: database: expected map, got nothing`)
+	path, ok := schema.FieldPath(err)
+	c.Assert(ok, gc.Equals, true)
+	c.Check(path, gc.DeepEquals, []string{".", "database"})
+
+	_, err = migrate(map[string]interface{}{"db_host": 1, "timeout": 1})
+	c.Assert(err, gc.ErrorMatches, `invalid old document: db_host: expected string, got int\(1\)`)
+	path, ok = schema.FieldPath(err)
+	c.Assert(ok, gc.Equals, true)
+	c.Check(path, gc.DeepEquals, []string{".", "db_host"})
+}
+
+func (s *migrateSuite) TestMigrateDuplicateMovesPanics(c *gc.C) {
+	c.Assert(func() {
+		schema.Migrate(oldFormat, newFormat, schema.MigrationRules{
+			Moves: map[string]string{"db_host": "database.host", "timeout": "database.host"},
+		})
+	}, gc.PanicMatches, `Migrate got moves of both "db_host" and "timeout" to "database.host"`)
+}
@@ -0,0 +1,349 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HostPort returns a Checker that accepts a "host:port" string, as used
+// for listen and dial addresses, and returns it in normalized form. The
+// host must be a valid host name or IP address, with IPv6 addresses
+// enclosed in brackets, and may be empty to mean all local addresses.
+// The port must be a number between 1 and 65535.
+func HostPort() Checker {
+	return hostPortC{}
+}
+
+// HostPortAddr holds the components of a "host:port" address. IPv6
+// addresses are held without brackets.
+type HostPortAddr struct {
+	Host string
+	Port int
+}
+
+// String returns the address in "host:port" form.
+func (a HostPortAddr) String() string {
+	return net.JoinHostPort(a.Host, strconv.Itoa(a.Port))
+}
+
+// HostPortStruct returns a Checker that accepts the same strings as
+// HostPort, and returns their components as a HostPortAddr.
+func HostPortStruct() Checker {
+	return hostPortC{asStruct: true}
+}
+
+type hostPortC struct {
+	asStruct bool
+}
+
+var hostnameRegexp = regexp.MustCompile(`^(?i:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)(?:\.(?i:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?))*\.?$`)
+
+func (c hostPortC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	host, port, err := parseHostPort(s)
+	if err == errBadPort {
+		return nil, errorf(path, "%q is not a valid host:port", s)
+	}
+	if err != nil {
+		return nil, errorf(path, "%q is not a valid host:port: %v", s, err)
+	}
+	addr := HostPortAddr{Host: host, Port: port}
+	if c.asStruct {
+		return addr, nil
+	}
+	return addr.String(), nil
+}
+
+// errBadPort is returned by parseHostPort when the port is missing or
+// invalid.
+var errBadPort = errors.New("bad port")
+
+func parseHostPort(s string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(s)
+	if err != nil {
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) {
+			if addrErr.Err == "missing port in address" {
+				return "", 0, errBadPort
+			}
+			return "", 0, errors.New(addrErr.Err)
+		}
+		return "", 0, err
+	}
+	if host != "" && net.ParseIP(host) == nil && (len(host) > 253 || !hostnameRegexp.MatchString(host)) {
+		return "", 0, errors.New("invalid host")
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, errBadPort
+	}
+	return host, port, nil
+}
+
+// PortOption holds an option for Port.
+type PortOption int
+
+// AllowZero makes Port accept port 0, commonly meaning any port.
+const AllowZero PortOption = 1
+
+// Port returns a Checker that accepts a TCP or UDP port number, as an
+// integer or a string holding a decimal integer such as "8080", and
+// returns it as an int. The port must be between 1 and 65535, or may
+// be 0 when the AllowZero option is provided. Service names such as
+// "http" are not looked up and are rejected.
+func Port(options ...PortOption) Checker {
+	var c portC
+	for _, opt := range options {
+		if opt == AllowZero {
+			c.allowZero = true
+		}
+	}
+	return c
+}
+
+type portC struct {
+	allowZero bool
+}
+
+func (c portC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil {
+		return nil, CoerceError{Expected: "port", Got: v, Path: path}
+	}
+	var port int64
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		port = rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		port = int64(rv.Uint())
+		if rv.Uint() > 65535 {
+			// Avoid overflowing into the valid range.
+			port = 65536
+		}
+	case reflect.String:
+		p, err := strconv.ParseInt(rv.String(), 10, 64)
+		if err != nil {
+			return nil, errorf(path, "invalid port %q", rv.String())
+		}
+		port = p
+	default:
+		return nil, CoerceError{Expected: "port", Got: v, Path: path}
+	}
+	min := int64(1)
+	if c.allowZero {
+		min = 0
+	}
+	if port < min || port > 65535 {
+		return nil, errorf(path, "port %v out of range [%d, 65535]", v, min)
+	}
+	return int(port), nil
+}
+
+// IPOption holds an option for IPAddress and CIDR.
+type IPOption int
+
+const (
+	// IPv4Only restricts addresses to IPv4.
+	IPv4Only IPOption = iota + 1
+	// IPv6Only restricts addresses to IPv6.
+	IPv6Only
+)
+
+// IPAddress returns a Checker that accepts a string holding an IPv4 or
+// IPv6 address, as parsed by net.ParseIP, and returns it in normalized
+// form, as in "2001:db8::1". The IPv4Only and IPv6Only options restrict
+// the accepted addresses to one family.
+func IPAddress(options ...IPOption) Checker {
+	return ipAddressC{ipFamily(options)}
+}
+
+type ipAddressC struct {
+	family IPOption
+}
+
+func (c ipAddressC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, errorf(path, "%q is not a valid IP address", s)
+	}
+	if want := wrongIPFamily(ip, c.family); want != "" {
+		return nil, errorf(path, "%q is not an %s address", s, want)
+	}
+	return ip.String(), nil
+}
+
+// CIDR returns a Checker that accepts a string holding an IP network in
+// CIDR notation, as parsed by net.ParseCIDR, and returns the network in
+// canonical form, so that "10.1.2.3/8" becomes "10.0.0.0/8". The
+// IPv4Only and IPv6Only options restrict the accepted networks to one
+// family.
+func CIDR(options ...IPOption) Checker {
+	return cidrC{ipFamily(options)}
+}
+
+type cidrC struct {
+	family IPOption
+}
+
+func (c cidrC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	ip, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, errorf(path, "%q is not a valid CIDR", s)
+	}
+	if want := wrongIPFamily(ip, c.family); want != "" {
+		return nil, errorf(path, "%q is not an %s network", s, want)
+	}
+	return ipNet.String(), nil
+}
+
+// ipFamily returns the address family selected by options, or zero if
+// any family is allowed.
+func ipFamily(options []IPOption) IPOption {
+	var family IPOption
+	for _, opt := range options {
+		family = opt
+	}
+	return family
+}
+
+// wrongIPFamily returns the name of the required family if ip isn't
+// in it, or the empty string otherwise.
+func wrongIPFamily(ip net.IP, family IPOption) string {
+	switch {
+	case family == IPv4Only && ip.To4() == nil:
+		return "IPv4"
+	case family == IPv6Only && ip.To4() != nil:
+		return "IPv6"
+	}
+	return ""
+}
+
+// NetworkMask returns a Checker that accepts an IPv4 network mask,
+// either in dotted-decimal form such as "255.255.255.0" or as a prefix
+// length such as "24" or "/24", and returns it as a net.IPMask. Masks
+// whose bits are not contiguous, such as "255.255.0.255", are rejected.
+func NetworkMask() Checker {
+	return networkMaskC{}
+}
+
+type networkMaskC struct{}
+
+func (c networkMaskC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	mask, err := parseNetworkMask(s)
+	if err != nil {
+		return nil, errorf(path, "invalid network mask %q: %v", s, err)
+	}
+	return mask, nil
+}
+
+func parseNetworkMask(s string) (net.IPMask, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		ip4 := ip.To4()
+		if ip4 == nil {
+			return nil, errors.New("not an IPv4 mask")
+		}
+		mask := net.IPMask(ip4)
+		if _, bits := mask.Size(); bits == 0 {
+			return nil, errors.New("mask bits must be contiguous")
+		}
+		return mask, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "/"))
+	if err != nil {
+		return nil, errors.New("expected dotted-decimal mask or prefix length")
+	}
+	if n < 0 || n > 32 {
+		return nil, fmt.Errorf("prefix length %d out of range [0, 32]", n)
+	}
+	return net.CIDRMask(n, 32), nil
+}
+
+// EmailOption holds an option for Email: either AllowDisplayName, or a
+// domain that addresses are allowed in.
+type EmailOption string
+
+// AllowDisplayName makes Email accept addresses with a display name, as
+// in "Bob <bob@example.com>".
+const AllowDisplayName EmailOption = "<allow display name>"
+
+// Email returns a Checker that accepts a string holding an email
+// address, as parsed by net/mail, and returns the bare address with its
+// domain in lower case, as in "Bob@example.com". A local part that
+// needs quoting keeps its quotes, as in `"bob smith"@example.com`.
+// Addresses with a display name, such as "Bob <Bob@Example.com>", are
+// rejected unless the AllowDisplayName option is provided, in which
+// case the display name is dropped. Any other options are domains, such
+// as "example.com", and when provided, addresses in other domains are
+// rejected.
+func Email(options ...EmailOption) Checker {
+	var c emailC
+	for _, opt := range options {
+		if opt == AllowDisplayName {
+			c.allowDisplayName = true
+		} else {
+			c.domains = append(c.domains, strings.ToLower(string(opt)))
+		}
+	}
+	return c
+}
+
+type emailC struct {
+	allowDisplayName bool
+	domains          []string
+}
+
+func (c emailC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return nil, errorf(path, "invalid email address %q: %v", s, err)
+	}
+	if !c.allowDisplayName && addr.Name != "" {
+		return nil, errorf(path, "display name not allowed in email address %q", s)
+	}
+	at := strings.LastIndex(addr.Address, "@")
+	local, domain := addr.Address[:at], strings.ToLower(addr.Address[at+1:])
+	if len(c.domains) > 0 {
+		permitted := false
+		for _, d := range c.domains {
+			if domain == d {
+				permitted = true
+				break
+			}
+		}
+		if !permitted {
+			return nil, errorf(path, "email domain %q not permitted", domain)
+		}
+	}
+	// Let net/mail quote the local part again if it needs it, as in
+	// "bob smith"@example.com, dropping the angle brackets around it.
+	out := (&mail.Address{Address: local + "@" + domain}).String()
+	return strings.TrimSuffix(strings.TrimPrefix(out, "<"), ">"), nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"net"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type netSuite struct{}
+
+var _ = gc.Suite(&netSuite{})
+
+func (s *netSuite) TestHostPort(c *gc.C) {
+	sch := schema.HostPort()
+
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"example.com:80", "example.com:80"},
+		{"10.0.0.1:08080", "10.0.0.1:8080"},
+		{"[::1]:17070", "[::1]:17070"},
+		{":443", ":443"},
+		{"localhost:1", "localhost:1"},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+}
+
+func (s *netSuite) TestHostPortErrors(c *gc.C) {
+	sch := schema.HostPort()
+
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{"example.com", `<path>: "example.com" is not a valid host:port`},
+		{"::1:80", `<path>: "::1:80" is not a valid host:port: too many colons in address`},
+		{"exa_mple.com:80", `<path>: "exa_mple.com:80" is not a valid host:port: invalid host`},
+		{"example.com:http", `<path>: "example.com:http" is not a valid host:port`},
+		{"example.com:0", `<path>: "example.com:0" is not a valid host:port`},
+		{"example.com:70000", `<path>: "example.com:70000" is not a valid host:port`},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(out, gc.IsNil)
+		c.Check(err.Error(), gc.Equals, test.err)
+	}
+
+	out, err := sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected string, got int(42)`)
+}
+
+func (s *netSuite) TestHostPortStruct(c *gc.C) {
+	sch := schema.HostPortStruct()
+
+	tests := []struct {
+		in  string
+		out schema.HostPortAddr
+	}{
+		{"example.com:80", schema.HostPortAddr{Host: "example.com", Port: 80}},
+		{"10.0.0.1:08080", schema.HostPortAddr{Host: "10.0.0.1", Port: 8080}},
+		{"[::1]:17070", schema.HostPortAddr{Host: "::1", Port: 17070}},
+		{":443", schema.HostPortAddr{Port: 443}},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+	c.Assert(schema.HostPortAddr{Host: "::1", Port: 17070}.String(), gc.Equals, "[::1]:17070")
+
+	out, err := sch.Coerce("example.com", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: "example.com" is not a valid host:port`)
+}
+
+func (s *netSuite) TestPort(c *gc.C) {
+	sch := schema.Port()
+	for _, in := range []interface{}{8080, int64(8080), uint16(8080), "8080"} {
+		out, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, 8080)
+	}
+	out, err := sch.Coerce(65535, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, 65535)
+
+	_, err = sch.Coerce(70000, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: port 70000 out of range \[1, 65535\]`)
+	_, err = sch.Coerce("-1", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: port -1 out of range \[1, 65535\]`)
+	_, err = sch.Coerce(uint64(1<<63), aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: port 9223372036854775808 out of range \[1, 65535\]`)
+	_, err = sch.Coerce(0, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: port 0 out of range \[1, 65535\]`)
+	_, err = sch.Coerce("http", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid port "http"`)
+	_, err = sch.Coerce(80.0, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected port, got float64\(80\)`)
+	_, err = sch.Coerce(nil, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected port, got nothing`)
+
+	sch = schema.Port(schema.AllowZero)
+	out, err = sch.Coerce("0", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, 0)
+	_, err = sch.Coerce(-1, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: port -1 out of range \[0, 65535\]`)
+}
+
+func (s *netSuite) TestIPAddress(c *gc.C) {
+	sch := schema.IPAddress()
+	tests := []struct{ in, out string }{
+		{"10.0.0.1", "10.0.0.1"},
+		{"2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1"},
+		{"::FFFF:192.0.2.1", "192.0.2.1"},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	_, err := sch.Coerce("999.0.0.1", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: "999.0.0.1" is not a valid IP address`)
+	_, err = sch.Coerce("10.0.0.0/8", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: "10.0.0.0/8" is not a valid IP address`)
+	_, err = sch.Coerce(nil, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got nothing`)
+
+	_, err = schema.IPAddress(schema.IPv4Only).Coerce("::1", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: "::1" is not an IPv4 address`)
+	_, err = schema.IPAddress(schema.IPv6Only).Coerce("127.0.0.1", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: "127.0.0.1" is not an IPv6 address`)
+	out, err := schema.IPAddress(schema.IPv6Only).Coerce("::1", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "::1")
+}
+
+func (s *netSuite) TestCIDR(c *gc.C) {
+	sch := schema.CIDR()
+	tests := []struct{ in, out string }{
+		{"10.1.2.3/8", "10.0.0.0/8"},
+		{"192.168.0.0/24", "192.168.0.0/24"},
+		{"2001:db8:0:0:1::/64", "2001:db8::/64"},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	_, err := sch.Coerce("10.0.0.1", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: "10.0.0.1" is not a valid CIDR`)
+	_, err = sch.Coerce("10.0.0.0/33", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: "10.0.0.0/33" is not a valid CIDR`)
+
+	_, err = schema.CIDR(schema.IPv4Only).Coerce("fd00::/8", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: "fd00::/8" is not an IPv4 network`)
+	_, err = schema.CIDR(schema.IPv6Only).Coerce("10.0.0.0/8", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: "10.0.0.0/8" is not an IPv6 network`)
+}
+
+func (s *netSuite) TestNetworkMask(c *gc.C) {
+	sch := schema.NetworkMask()
+
+	tests := []struct {
+		in  string
+		out net.IPMask
+	}{
+		{"255.255.255.0", net.CIDRMask(24, 32)},
+		{"255.255.240.0", net.CIDRMask(20, 32)},
+		{"0.0.0.0", net.CIDRMask(0, 32)},
+		{"24", net.CIDRMask(24, 32)},
+		{"/32", net.CIDRMask(32, 32)},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.DeepEquals, test.out)
+	}
+
+	errTests := []struct {
+		in  interface{}
+		err string
+	}{
+		{"255.255.0.255", `<path>: invalid network mask "255.255.0.255": mask bits must be contiguous`},
+		{"ffff::", `<path>: invalid network mask "ffff::": not an IPv4 mask`},
+		{"33", `<path>: invalid network mask "33": prefix length 33 out of range \[0, 32\]`},
+		{"mask", `<path>: invalid network mask "mask": expected dotted-decimal mask or prefix length`},
+		{24, `<path>: expected string, got int\(24\)`},
+	}
+	for i, test := range errTests {
+		c.Logf("test %d: %v", i, test.in)
+		_, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *netSuite) TestEmail(c *gc.C) {
+	sch := schema.Email()
+
+	out, err := sch.Coerce("Bob@Example.COM", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "Bob@example.com")
+
+	_, err = sch.Coerce("Bob <bob@example.com>", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: display name not allowed in email address "Bob <bob@example.com>"`)
+
+	// Addresses normalized by net/mail have no display name.
+	out, err = sch.Coerce(`"bob smith"@Example.com`, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, `"bob smith"@example.com`)
+	out, err = sch.Coerce("<bob@example.com>", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "bob@example.com")
+
+	_, err = sch.Coerce("bob", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid email address "bob": mail: .*`)
+
+	_, err = sch.Coerce(42, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(42\)`)
+
+	out, err = schema.Email(schema.AllowDisplayName).Coerce("Bob <bob@Example.com>", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "bob@example.com")
+}
+
+func (s *netSuite) TestEmailDomains(c *gc.C) {
+	sch := schema.Email("example.com", "Example.org", schema.AllowDisplayName)
+
+	out, err := sch.Coerce("alice@EXAMPLE.org", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "alice@example.org")
+
+	out, err = sch.Coerce("Alice <alice@example.com>", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "alice@example.com")
+
+	_, err = sch.Coerce("mallory@evil.com", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: email domain "evil.com" not permitted`)
+
+	_, err = sch.Coerce("mallory@sub.example.com", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: email domain "sub.example.com" not permitted`)
+}
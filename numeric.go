@@ -4,34 +4,63 @@
 package schema
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 )
 
-// Bool returns a Checker that accepts boolean values only.
+// Bool returns a Checker that accepts boolean values, and strings
+// commonly used for them in configuration: those accepted by
+// strconv.ParseBool, and "yes", "no", "on" and "off" in any case. The
+// coerced value has type bool.
 func Bool() Checker {
 	return boolC{}
 }
 
-type boolC struct{}
+type boolC struct {
+	strict bool
+}
+
+// StrictBool returns a Checker that acts as the one returned by Bool,
+// but only accepts the strings accepted by strconv.ParseBool.
+func StrictBool() Checker {
+	return boolC{strict: true}
+}
 
 func (c boolC) Coerce(v interface{}, path []string) (interface{}, error) {
 	if v != nil {
 		switch reflect.TypeOf(v).Kind() {
 		case reflect.Bool:
-			return v, nil
+			return reflect.ValueOf(v).Bool(), nil
 		case reflect.String:
-			val, err := strconv.ParseBool(reflect.ValueOf(v).String())
+			s := reflect.ValueOf(v).String()
+			val, err := strconv.ParseBool(s)
 			if err == nil {
 				return val, nil
 			}
+			if c.strict {
+				break
+			}
+			switch strings.ToLower(s) {
+			case "yes", "on":
+				return true, nil
+			case "no", "off":
+				return false, nil
+			}
+			return nil, errorf(path, "%q is not a valid boolean", s)
 		}
 	}
-	return nil, error_{"bool", v, path}
+	return nil, CoerceError{Expected: "bool", Got: v, Path: path}
 }
 
-// Int returns a Checker that accepts any integer value, and returns
-// the same value consistently typed as an int64.
+// Int returns a Checker that accepts any integer value, including a
+// json.Number holding one, and returns the same value consistently
+// typed as an int64.
 func Int() Checker {
 	return intC{}
 }
@@ -40,7 +69,14 @@ type intC struct{}
 
 func (c intC) Coerce(v interface{}, path []string) (interface{}, error) {
 	if v == nil {
-		return nil, error_{"int", v, path}
+		return nil, CoerceError{Expected: "int", Got: v, Path: path}
+	}
+	if n, ok := v.(json.Number); ok {
+		val, err := n.Int64()
+		if err != nil {
+			return nil, CoerceError{Expected: "int", Got: v, Path: path}
+		}
+		return val, nil
 	}
 	switch reflect.TypeOf(v).Kind() {
 	case reflect.Int:
@@ -53,10 +89,10 @@ func (c intC) Coerce(v interface{}, path []string) (interface{}, error) {
 		if err == nil {
 			return val, nil
 		} else {
-			return nil, error_{"int", v, path}
+			return nil, CoerceError{Expected: "int", Got: v, Path: path}
 		}
 	default:
-		return nil, error_{"int", v, path}
+		return nil, CoerceError{Expected: "int", Got: v, Path: path}
 	}
 	return reflect.ValueOf(v).Int(), nil
 }
@@ -72,7 +108,7 @@ type uintC struct{}
 
 func (c uintC) Coerce(v interface{}, path []string) (interface{}, error) {
 	if v == nil {
-		return nil, error_{"uint", v, path}
+		return nil, CoerceError{Expected: "uint", Got: v, Path: path}
 	}
 	switch reflect.TypeOf(v).Kind() {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
@@ -80,7 +116,7 @@ func (c uintC) Coerce(v interface{}, path []string) (interface{}, error) {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		val := reflect.ValueOf(v).Int()
 		if val < 0 {
-			return nil, error_{"uint", v, path}
+			return nil, CoerceError{Expected: "uint", Got: v, Path: path}
 		}
 		// All positive int64 values fit into uint64.
 		return uint64(val), nil
@@ -89,10 +125,10 @@ func (c uintC) Coerce(v interface{}, path []string) (interface{}, error) {
 		if err == nil {
 			return val, nil
 		} else {
-			return nil, error_{"uint", v, path}
+			return nil, CoerceError{Expected: "uint", Got: v, Path: path}
 		}
 	default:
-		return nil, error_{"uint", v, path}
+		return nil, CoerceError{Expected: "uint", Got: v, Path: path}
 	}
 }
 
@@ -125,7 +161,7 @@ func (c forceIntC) Coerce(v interface{}, path []string) (interface{}, error) {
 			return int(reflect.ValueOf(v).Float()), nil
 		}
 	}
-	return nil, error_{"number", v, path}
+	return nil, CoerceError{Expected: "number", Got: v, Path: path}
 }
 
 // ForceUint returns a Checker that accepts any integer or float value, and
@@ -151,7 +187,7 @@ func (c forceUintC) Coerce(v interface{}, path []string) (interface{}, error) {
 			floatValue, err := strconv.ParseFloat(vstr, 64)
 			if err == nil {
 				if floatValue < 0 {
-					return nil, error_{"uint", v, path}
+					return nil, CoerceError{Expected: "uint", Got: v, Path: path}
 				}
 				return uint64(floatValue), nil
 			}
@@ -160,23 +196,24 @@ func (c forceUintC) Coerce(v interface{}, path []string) (interface{}, error) {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			val := reflect.ValueOf(v).Int()
 			if val < 0 {
-				return nil, error_{"uint", v, path}
+				return nil, CoerceError{Expected: "uint", Got: v, Path: path}
 			}
 			// All positive int64 values fit into uint64.
 			return uint64(val), nil
 		case reflect.Float32, reflect.Float64:
 			val := reflect.ValueOf(v).Float()
 			if val < 0 {
-				return nil, error_{"uint", v, path}
+				return nil, CoerceError{Expected: "uint", Got: v, Path: path}
 			}
 			return uint64(val), nil
 		}
 	}
-	return nil, error_{"uint", v, path}
+	return nil, CoerceError{Expected: "uint", Got: v, Path: path}
 }
 
-// Float returns a Checker that accepts any float value, and returns
-// the same value consistently typed as a float64.
+// Float returns a Checker that accepts any float value, including a
+// json.Number, and returns the same value consistently typed as a
+// float64.
 func Float() Checker {
 	return floatC{}
 }
@@ -185,15 +222,373 @@ type floatC struct{}
 
 func (c floatC) Coerce(v interface{}, path []string) (interface{}, error) {
 	if v == nil {
-		return nil, error_{"float", v, path}
+		return nil, CoerceError{Expected: "float", Got: v, Path: path}
+	}
+	if n, ok := v.(json.Number); ok {
+		val, err := n.Float64()
+		if err != nil {
+			return nil, CoerceError{Expected: "float", Got: v, Path: path}
+		}
+		return val, nil
 	}
 	switch reflect.TypeOf(v).Kind() {
         case reflect.Float32, reflect.Float64:
         case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
         case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 	default:
-		return nil, error_{"float", v, path}
+		return nil, CoerceError{Expected: "float", Got: v, Path: path}
 	}
 	var floatValue float64
 	return reflect.ValueOf(v).Convert( reflect.TypeOf(floatValue) ).Float() , nil
 }
+
+// NumberRange returns a Checker that accepts a number, or a string
+// holding a number such as "2.5", and returns it as a float64 if it is
+// between min and max, inclusive. Either bound may be an infinity to
+// leave that side of the range open, as done by Min and Max.
+func NumberRange(min, max float64) Checker {
+	return rangeC{numberC{}, min, max}
+}
+
+// Min returns a Checker that acts as the one returned by NumberRange,
+// with no upper bound.
+func Min(min float64) Checker {
+	return rangeC{numberC{}, min, math.Inf(1)}
+}
+
+// Max returns a Checker that acts as the one returned by NumberRange,
+// with no lower bound.
+func Max(max float64) Checker {
+	return rangeC{numberC{}, math.Inf(-1), max}
+}
+
+// IntRange returns a Checker that accepts an integer, as Int does, and
+// returns it as an int if it is between min and max, inclusive.
+func IntRange(min, max int) Checker {
+	return intRangeC{rangeC{Int(), float64(min), float64(max)}}
+}
+
+type numberC struct{}
+
+func (c numberC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v != nil && reflect.TypeOf(v).Kind() == reflect.String {
+		f, err := strconv.ParseFloat(reflect.ValueOf(v).String(), 64)
+		if err != nil {
+			return nil, CoerceError{Expected: "number", Got: v, Path: path}
+		}
+		return f, nil
+	}
+	f, err := Float().Coerce(v, path)
+	if err != nil {
+		return nil, CoerceError{Expected: "number", Got: v, Path: path}
+	}
+	return f, nil
+}
+
+type rangeC struct {
+	checker  Checker
+	min, max float64
+}
+
+func (c rangeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	newv, err := c.checker.Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Float().Coerce(newv, path)
+	if err != nil {
+		return nil, err
+	}
+	if x := f.(float64); !(x >= c.min && x <= c.max) {
+		switch {
+		case math.IsInf(c.max, 1):
+			return nil, errorf(path, "%v is less than the minimum %v", newv, c.min)
+		case math.IsInf(c.min, -1):
+			return nil, errorf(path, "%v is greater than the maximum %v", newv, c.max)
+		}
+		return nil, errorf(path, "%v is not in the range [%v, %v]", newv, c.min, c.max)
+	}
+	return newv, nil
+}
+
+type intRangeC struct {
+	rangeC
+}
+
+func (c intRangeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	newv, err := c.rangeC.Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	return int(newv.(int64)), nil
+}
+
+// LocalizedFloat returns a Checker that accepts a string holding a
+// number written with decimalSep as the decimal separator, such as
+// "3,14", as found in data exported from spreadsheets in many locales,
+// and returns it as a float64. If thousandsSep is provided, it may be
+// used to separate groups of three digits in the integer part, as in
+// "1.234.567,89". Numbers that are not strings are accepted as by
+// Float.
+func LocalizedFloat(decimalSep rune, thousandsSep ...rune) Checker {
+	c := localizedFloatC{decimalSep: decimalSep}
+	switch len(thousandsSep) {
+	case 0:
+	case 1:
+		if thousandsSep[0] == decimalSep {
+			panic("LocalizedFloat got the same decimal and thousands separators")
+		}
+		c.thousandsSep = thousandsSep[0]
+	default:
+		panic("LocalizedFloat got more than one thousands separator")
+	}
+	return c
+}
+
+type localizedFloatC struct {
+	decimalSep   rune
+	thousandsSep rune
+}
+
+func (c localizedFloatC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return Float().Coerce(v, path)
+	}
+	s := reflect.ValueOf(v).String()
+	f, err := c.parse(s)
+	if err != nil {
+		return nil, errorf(path, "invalid number %q: %v", s, err)
+	}
+	return f, nil
+}
+
+func (c localizedFloatC) parse(s string) (float64, error) {
+	var buf strings.Builder
+	// group holds the number of digits since the last thousands
+	// separator, or -1 if none was found yet.
+	group, digits := -1, 0
+	decimal := false
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+			if group >= 0 && !decimal {
+				group++
+			}
+			buf.WriteRune(r)
+		case (r == '-' || r == '+') && i == 0:
+			buf.WriteRune(r)
+		case r == c.decimalSep && !decimal:
+			if group >= 0 && group != 3 {
+				return 0, fmt.Errorf("misplaced thousands separator")
+			}
+			decimal = true
+			buf.WriteByte('.')
+		case r == c.thousandsSep && c.thousandsSep != 0 && !decimal:
+			if digits == 0 || (group < 0 && digits > 3) || (group >= 0 && group != 3) {
+				return 0, fmt.Errorf("misplaced thousands separator")
+			}
+			group = 0
+		default:
+			return 0, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	if digits == 0 {
+		return 0, fmt.Errorf("no digits")
+	}
+	if group >= 0 && !decimal && group != 3 {
+		return 0, fmt.Errorf("misplaced thousands separator")
+	}
+	return strconv.ParseFloat(buf.String(), 64)
+}
+
+// Quantiles returns a Checker that accepts a comma-separated string of
+// quantiles such as "0.5,0.9,0.99", or a list of numbers, and returns
+// the quantiles sorted in increasing order as a []float64. Every
+// quantile must lie strictly between 0 and 1, and may only appear once.
+func Quantiles() Checker {
+	return quantilesC{}
+}
+
+type quantilesC struct{}
+
+func (c quantilesC) Coerce(v interface{}, path []string) (interface{}, error) {
+	var qs []float64
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.String:
+		for _, s := range strings.Split(rv.String(), ",") {
+			s = strings.TrimSpace(s)
+			q, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, errorf(path, "invalid quantile %q", s)
+			}
+			qs = append(qs, q)
+		}
+	case reflect.Slice:
+		elems, err := List(Float()).Coerce(v, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range elems.([]interface{}) {
+			qs = append(qs, q.(float64))
+		}
+	default:
+		return nil, CoerceError{Expected: "string or list", Got: v, Path: path}
+	}
+	seen := make(map[float64]bool, len(qs))
+	for _, q := range qs {
+		if !(q > 0 && q < 1) {
+			return nil, errorf(path, "quantile %v not in range (0, 1)", q)
+		}
+		if seen[q] {
+			return nil, errorf(path, "duplicate quantile %v", q)
+		}
+		seen[q] = true
+	}
+	sort.Float64s(qs)
+	return qs, nil
+}
+
+// IncreasingSequence returns a Checker that accepts a list of numbers,
+// each strictly greater than the one before it, as for histogram bucket
+// boundaries, and returns them as a []float64.
+func IncreasingSequence() Checker {
+	return sequenceC{strict: true}
+}
+
+// NonDecreasingSequence returns a Checker that acts as the one returned
+// by IncreasingSequence, but also allows adjacent values to be equal.
+func NonDecreasingSequence() Checker {
+	return sequenceC{strict: false}
+}
+
+type sequenceC struct {
+	strict bool
+}
+
+func (c sequenceC) Coerce(v interface{}, path []string) (interface{}, error) {
+	elems, err := List(Float()).Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	seq := make([]float64, len(elems.([]interface{})))
+	for i, f := range elems.([]interface{}) {
+		seq[i] = f.(float64)
+		if i == 0 {
+			continue
+		}
+		prev := seq[i-1]
+		if c.strict && seq[i] <= prev {
+			return nil, errorf(path, "expected increasing sequence, got [%d] %v followed by [%d] %v", i-1, prev, i, seq[i])
+		}
+		if !c.strict && seq[i] < prev {
+			return nil, errorf(path, "expected non-decreasing sequence, got [%d] %v followed by [%d] %v", i-1, prev, i, seq[i])
+		}
+	}
+	return seq, nil
+}
+
+// Rate returns a Checker that accepts a string holding a rate such as
+// "100/s", "50/min" or "1000/hour", and returns it normalized to a
+// number of events per second as a float64. The period unit may be one
+// of "s", "sec", "second", "m", "min", "minute", "h", "hour", "d" or
+// "day", and the count must not be negative.
+func Rate() Checker {
+	return rateC{}
+}
+
+type rateC struct{}
+
+var ratePeriods = map[string]float64{
+	"s":      1,
+	"sec":    1,
+	"second": 1,
+	"m":      60,
+	"min":    60,
+	"minute": 60,
+	"h":      60 * 60,
+	"hour":   60 * 60,
+	"d":      24 * 60 * 60,
+	"day":    24 * 60 * 60,
+}
+
+func (c rateC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	count, unit, ok := strings.Cut(s, "/")
+	if !ok {
+		return nil, errorf(path, "invalid rate %q: expected count/period", s)
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(count), 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return nil, errorf(path, "invalid rate %q: invalid count %q", s, count)
+	}
+	period, ok := ratePeriods[strings.TrimSpace(unit)]
+	if !ok {
+		return nil, errorf(path, "invalid rate %q: unknown period unit %q", s, unit)
+	}
+	return n / period, nil
+}
+
+// UnitValue returns a Checker that accepts a string holding a number
+// followed by a unit, such as "1.5GB" or "30 s", and returns the result
+// of calling the conversion function associated with the unit in units
+// with the number. It generalizes checkers such as Size and Rate to
+// arbitrary units. A number without a unit is only accepted when units
+// has an entry for the empty unit.
+func UnitValue(units map[string]func(float64) interface{}) Checker {
+	names := make([]string, 0, len(units))
+	for unit := range units {
+		names = append(names, unit)
+	}
+	sort.Strings(names)
+	return unitValueC{units, names}
+}
+
+type unitValueC struct {
+	units map[string]func(float64) interface{}
+	names []string
+}
+
+var unitValueRegexp = regexp.MustCompile(`^\s*([-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][-+]?[0-9]+)?)\s*(.*?)\s*$`)
+
+func (c unitValueC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	m := unitValueRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, errorf(path, "invalid value %q: expected number followed by unit", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return nil, parseError(path, "float", err)
+	}
+	convert, ok := c.units[m[2]]
+	if !ok {
+		return nil, errorf(path, "unknown unit %q in %q, expected one of %q", m[2], s, c.names)
+	}
+	return convert(n), nil
+}
+
+// TypedQuantity returns a Checker that accepts a string holding a number
+// followed by one of the units in units, such as "2.4GHz" or "100 kbps",
+// and returns the quantity converted to the base unit as a float64, by
+// multiplying the number by the factor associated with its unit. The
+// factor of the base unit must be 1, so that for instance frequencies
+// could use the units {"Hz": 1, "kHz": 1e3, "MHz": 1e6} with base "Hz".
+// TypedQuantity panics if it is not.
+func TypedQuantity(units map[string]float64, base string) Checker {
+	if units[base] != 1 {
+		panic(fmt.Sprintf("TypedQuantity got base unit %q without a factor of 1", base))
+	}
+	convs := make(map[string]func(float64) interface{}, len(units))
+	for unit, factor := range units {
+		factor := factor
+		convs[unit] = func(f float64) interface{} { return f * factor }
+	}
+	return UnitValue(convs)
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// CoerceOptions holds options for CoerceWithOptions. The zero value is
+// ready to use.
+type CoerceOptions struct {
+	// Features holds the features enabled for fields of FieldMaps
+	// returned by FeatureGated.
+	Features map[string]bool
+
+	// StrictKeys makes FieldMaps reject unknown keys, as a
+	// StrictFieldMap does, except those returned by PreserveUnknown.
+	StrictKeys bool
+
+	// NoStringConversion makes the Bool, Int, Uint, ForceInt,
+	// ForceUint and Float checkers reject strings, rather than parse
+	// them, so that numbers and booleans must be given as such.
+	NoStringConversion bool
+
+	// TrimStrings removes white space surrounding string values
+	// before they are processed.
+	TrimStrings bool
+
+	types map[reflect.Type]func(v interface{}) (interface{}, error)
+}
+
+// LenientProfile returns options for input written loosely, as by
+// hand: white space surrounding strings is removed, and numbers and
+// booleans may be given as strings, as they may by default. Maps with
+// interface{} keys, as produced by YAML decoders, are accepted by
+// FieldMaps whatever the options.
+func LenientProfile() CoerceOptions {
+	return CoerceOptions{TrimStrings: true}
+}
+
+// StrictProfile returns options for input that must have exact types,
+// as produced by programs: FieldMaps reject unknown keys, and numbers
+// and booleans given as strings are rejected.
+func StrictProfile() CoerceOptions {
+	return CoerceOptions{StrictKeys: true, NoStringConversion: true}
+}
+
+// RegisterType registers handler to be called for every input value of
+// type t, such as a domain-specific ID type, by CoerceWithOptions. The
+// value returned by handler, typically a string or other plain value,
+// is then processed by the checkers in place of the original one. This
+// allows schemas to validate already-typed Go values without wrapping
+// their checkers. Registering a type again replaces its handler.
+func (o *CoerceOptions) RegisterType(t reflect.Type, handler func(v interface{}) (interface{}, error)) {
+	if o.types == nil {
+		o.types = make(map[reflect.Type]func(v interface{}) (interface{}, error))
+	}
+	o.types[t] = handler
+}
+
+// CoerceWithOptions coerces v with c, as c.Coerce(v, path) does, with
+// the features and other options in opts applied wherever features
+// reach FieldMaps (see FeatureGated), after applying the handlers registered
+// in opts to the values of registered types found in v. Values within
+// maps and lists are handled too, recursively, but map keys are not.
+// Maps and lists holding a handled value are passed on to c as
+// map[interface{}]interface{} and []interface{} respectively. An error
+// returned by a handler is reported along with the path of the value.
+func CoerceWithOptions(c Checker, v interface{}, path []string, opts *CoerceOptions) (interface{}, error) {
+	if opts == nil {
+		return c.Coerce(v, path)
+	}
+	if len(opts.types) > 0 {
+		newv, _, err := opts.normalize(v, path)
+		if err != nil {
+			return nil, err
+		}
+		v = newv
+	}
+	return coerceField(c, v, path, &coerceState{opts: *opts})
+}
+
+// normalize returns v with the registered handlers applied to it, and
+// whether any of them was called.
+func (o *CoerceOptions) normalize(v interface{}, path []string) (interface{}, bool, error) {
+	if v == nil {
+		return nil, false, nil
+	}
+	if handler, ok := o.types[reflect.TypeOf(v)]; ok {
+		newv, err := handler(v)
+		if err != nil {
+			return nil, false, errorf(path, "%v", err)
+		}
+		return newv, true, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		out := make(map[interface{}]interface{}, rv.Len())
+		changed := false
+		for _, k := range rv.MapKeys() {
+			kpath := append(path[:len(path):len(path)], ".", fmt.Sprint(k.Interface()))
+			elem, elemChanged, err := o.normalize(rv.MapIndex(k).Interface(), kpath)
+			if err != nil {
+				return nil, false, err
+			}
+			out[k.Interface()] = elem
+			changed = changed || elemChanged
+		}
+		if changed {
+			return out, true, nil
+		}
+	case reflect.Slice:
+		out := make([]interface{}, rv.Len())
+		changed := false
+		for i := range out {
+			ipath := append(path[:len(path):len(path)], "[", strconv.Itoa(i), "]")
+			elem, elemChanged, err := o.normalize(rv.Index(i).Interface(), ipath)
+			if err != nil {
+				return nil, false, err
+			}
+			out[i] = elem
+			changed = changed || elemChanged
+		}
+		if changed {
+			return out, true, nil
+		}
+	}
+	return v, false, nil
+}
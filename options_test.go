@@ -0,0 +1,244 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"fmt"
+	"reflect"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type optionsSuite struct{}
+
+var _ = gc.Suite(&optionsSuite{})
+
+type machineID struct {
+	n int
+}
+
+func (s *optionsSuite) TestCoerceWithOptions(c *gc.C) {
+	var opts schema.CoerceOptions
+	opts.RegisterType(reflect.TypeOf(machineID{}), func(v interface{}) (interface{}, error) {
+		id := v.(machineID)
+		if id.n < 0 {
+			return nil, fmt.Errorf("invalid machine id %d", id.n)
+		}
+		return fmt.Sprintf("machine-%d", id.n), nil
+	})
+
+	sch := schema.FieldMap(schema.Fields{
+		"machine":  schema.String(),
+		"machines": schema.List(schema.String()),
+		"count":    schema.Int(),
+	}, nil)
+
+	v := map[string]interface{}{
+		"machine":  machineID{0},
+		"machines": []machineID{{1}, {2}},
+		"count":    2,
+	}
+	out, err := schema.CoerceWithOptions(sch, v, aPath, &opts)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{
+		"machine":  "machine-0",
+		"machines": []interface{}{"machine-1", "machine-2"},
+		"count":    int64(2),
+	})
+
+	// Without the handler, the checkers see the original values.
+	_, err = schema.CoerceWithOptions(sch, v, aPath, nil)
+	c.Check(err, gc.ErrorMatches, `<path>\.machine.*: expected string, got schema_test\.machineID.*`)
+
+	v["machines"] = []machineID{{1}, {-1}}
+	_, err = schema.CoerceWithOptions(sch, v, aPath, &opts)
+	c.Check(err, gc.ErrorMatches, `<path>\.machines\[1\]: invalid machine id -1`)
+}
+
+func (s *optionsSuite) TestRegisterTypeReplacesHandler(c *gc.C) {
+	var opts schema.CoerceOptions
+	opts.RegisterType(reflect.TypeOf(machineID{}), func(v interface{}) (interface{}, error) {
+		return "first", nil
+	})
+	opts.RegisterType(reflect.TypeOf(machineID{}), func(v interface{}) (interface{}, error) {
+		return "second", nil
+	})
+	out, err := schema.CoerceWithOptions(schema.String(), machineID{}, aPath, &opts)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "second")
+}
+
+func (s *optionsSuite) TestFeatureGated(c *gc.C) {
+	sch := schema.FeatureGated(schema.StrictFieldMap(schema.Fields{
+		"name":    schema.String(),
+		"tracing": schema.Bool(),
+		"region":  schema.String(),
+	}, schema.Defaults{
+		"tracing": false,
+		"region":  schema.Omit,
+	}), map[string]schema.FeatureRule{
+		"tracing": {Feature: "observability"},
+		"region":  {Feature: "multi-region", Required: true},
+	})
+
+	// With Coerce, all features are disabled.
+	out, err := sch.Coerce(map[string]interface{}{"name": "a"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"name": "a"})
+
+	_, err = sch.Coerce(map[string]interface{}{"name": "a", "tracing": true}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.tracing: field not allowed as feature "observability" is disabled`)
+
+	opts := &schema.CoerceOptions{
+		Features: map[string]bool{"observability": true},
+	}
+	out, err = schema.CoerceWithOptions(sch, map[string]interface{}{"name": "a"}, aPath, opts)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"name": "a", "tracing": false})
+
+	out, err = schema.CoerceWithOptions(sch, map[string]interface{}{"name": "a", "tracing": "true"}, aPath, opts)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"name": "a", "tracing": true})
+
+	opts.Features["multi-region"] = true
+	_, err = schema.CoerceWithOptions(sch, map[string]interface{}{"name": "a"}, aPath, opts)
+	c.Check(err, gc.ErrorMatches, `<path>\.region: field required as feature "multi-region" is enabled`)
+
+	out, err = schema.CoerceWithOptions(sch, map[string]interface{}{"name": "a", "region": "eu"}, aPath, opts)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"name": "a", "tracing": false, "region": "eu"})
+}
+
+func (s *optionsSuite) TestFeatureGatedNested(c *gc.C) {
+	inner := schema.FeatureGated(schema.FieldMap(schema.Fields{
+		"beta": schema.Int(),
+	}, nil), map[string]schema.FeatureRule{
+		"beta": {Feature: "beta"},
+	})
+	sch := schema.FieldMap(schema.Fields{"inner": inner}, nil)
+
+	opts := &schema.CoerceOptions{Features: map[string]bool{"beta": true}}
+	out, err := schema.CoerceWithOptions(sch, map[string]interface{}{
+		"inner": map[string]interface{}{"beta": 1},
+	}, nil, opts)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{
+		"inner": map[string]interface{}{"beta": int64(1)},
+	})
+
+	_, err = sch.Coerce(map[string]interface{}{
+		"inner": map[string]interface{}{"beta": 1},
+	}, nil)
+	c.Check(err, gc.ErrorMatches, `inner\.beta: field not allowed as feature "beta" is disabled`)
+}
+
+func (s *optionsSuite) TestFeatureGatedInContainers(c *gc.C) {
+	inner := schema.FeatureGated(schema.FieldMap(schema.Fields{
+		"beta": schema.Int(),
+	}, nil), map[string]schema.FeatureRule{
+		"beta": {Feature: "beta"},
+	})
+	var tree schema.Checker
+	tree = schema.FieldMap(schema.Fields{
+		"children": schema.List(schema.Lazy(func() schema.Checker { return tree })),
+		"leaf":     inner,
+	}, schema.Defaults{"children": schema.Omit, "leaf": schema.Omit})
+	opts := &schema.CoerceOptions{Features: map[string]bool{"beta": true}}
+	elem := map[string]interface{}{"beta": 1}
+
+	tests := []struct {
+		about string
+		sch   schema.Checker
+		in    interface{}
+		out   interface{}
+	}{{
+		about: "list",
+		sch:   schema.List(inner),
+		in:    []interface{}{elem},
+		out:   []interface{}{map[string]interface{}{"beta": int64(1)}},
+	}, {
+		about: "map",
+		sch:   schema.Map(schema.String(), inner),
+		in:    map[string]interface{}{"a": elem},
+		out:   map[interface{}]interface{}{"a": map[string]interface{}{"beta": int64(1)}},
+	}, {
+		about: "string map",
+		sch:   schema.StringMap(inner),
+		in:    map[string]interface{}{"a": elem},
+		out:   map[string]interface{}{"a": map[string]interface{}{"beta": int64(1)}},
+	}, {
+		about: "one of",
+		sch:   schema.OneOf(schema.Int(), inner),
+		in:    elem,
+		out:   map[string]interface{}{"beta": int64(1)},
+	}, {
+		about: "nullable",
+		sch:   schema.Nullable(inner),
+		in:    elem,
+		out:   map[string]interface{}{"beta": int64(1)},
+	}, {
+		about: "lazy",
+		sch:   tree,
+		in:    map[string]interface{}{"children": []interface{}{map[string]interface{}{"leaf": elem}}},
+		out: map[string]interface{}{"children": []interface{}{
+			map[string]interface{}{"leaf": map[string]interface{}{"beta": int64(1)}},
+		}},
+	}}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.about)
+		out, err := schema.CoerceWithOptions(test.sch, test.in, nil, opts)
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.DeepEquals, test.out)
+
+		_, err = test.sch.Coerce(test.in, nil)
+		c.Check(err, gc.NotNil)
+	}
+}
+
+func (s *optionsSuite) TestProfiles(c *gc.C) {
+	sch := schema.FieldMap(schema.Fields{
+		"name": schema.String(),
+		"port": schema.Int(),
+		"tags": schema.List(schema.String()),
+	}, schema.Defaults{"port": "8080", "tags": schema.Omit})
+	in := map[string]interface{}{"name": " x ", "port": "80", "tags": []interface{}{" a"}}
+
+	out, err := schema.CoerceWithOptions(sch, in, nil, nil)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{
+		"name": " x ", "port": int64(80), "tags": []interface{}{" a"},
+	})
+
+	lenient := schema.LenientProfile()
+	out, err = schema.CoerceWithOptions(sch, in, nil, &lenient)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{
+		"name": "x", "port": int64(80), "tags": []interface{}{"a"},
+	})
+
+	strict := schema.StrictProfile()
+	_, err = schema.CoerceWithOptions(sch, in, nil, &strict)
+	c.Check(err, gc.ErrorMatches, `port: expected int, got string\("80"\)`)
+
+	// Defaults are not input, so strings in them are still converted.
+	out, err = schema.CoerceWithOptions(sch, map[string]interface{}{"name": "x"}, nil, &strict)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"name": "x", "port": int64(8080)})
+
+	_, err = schema.CoerceWithOptions(sch, map[string]interface{}{"name": "x", "nmae": "y"}, nil, &strict)
+	c.Check(err, gc.ErrorMatches, `unknown key "nmae" \(value "y"\)`)
+	_, err = schema.CoerceWithOptions(sch, map[string]interface{}{"name": "x", "nmae": "y"}, nil, &lenient)
+	c.Check(err, gc.IsNil)
+}
+
+func (s *optionsSuite) TestFeatureGatedPanics(c *gc.C) {
+	c.Check(func() {
+		schema.FeatureGated(schema.String(), nil)
+	}, gc.PanicMatches, "FeatureGated got a non-FieldMap checker")
+	c.Check(func() {
+		schema.FeatureGated(schema.FieldMap(nil, nil), map[string]schema.FeatureRule{"x": {Feature: "f"}})
+	}, gc.PanicMatches, `FeatureGated got a rule for unknown field "x"`)
+}
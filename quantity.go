@@ -0,0 +1,62 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Quantity returns a Checker that accepts a Kubernetes resource
+// quantity such as "500m", "1.5Gi" or "1e3", and returns it as a string
+// in canonical form: a leading "+", redundant leading zeros and
+// trailing fractional zeros are dropped from the number, so "+01.50Gi"
+// becomes "1.5Gi". The suffix must be one recognized by Kubernetes:
+// a binary suffix (Ki, Mi, Gi, Ti, Pi, Ei), a decimal suffix (n, u, m,
+// k, M, G, T, P, E) or a decimal exponent (e3, E-2). Anything else,
+// such as "10Gim", is rejected with an error naming the suffix.
+func Quantity() Checker {
+	return quantityC{}
+}
+
+type quantityC struct{}
+
+var (
+	quantityRegexp         = regexp.MustCompile(`^([+-]?)([0-9]*)(?:\.([0-9]*))?(.*)$`)
+	quantityExponentRegexp = regexp.MustCompile(`^[eE][+-]?[0-9]+$`)
+	quantitySuffixes       = map[string]bool{
+		"Ki": true, "Mi": true, "Gi": true, "Ti": true, "Pi": true, "Ei": true,
+		"n": true, "u": true, "m": true, "": true, "k": true,
+		"M": true, "G": true, "T": true, "P": true, "E": true,
+	}
+)
+
+func (c quantityC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	m := quantityRegexp.FindStringSubmatch(s)
+	sign, whole, frac, suffix := m[1], m[2], m[3], m[4]
+	if whole == "" && frac == "" {
+		return nil, errorf(path, "invalid quantity %q", s)
+	}
+	if !quantitySuffixes[suffix] && !quantityExponentRegexp.MatchString(suffix) {
+		return nil, errorf(path, "invalid quantity suffix %q in %q", suffix, s)
+	}
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	frac = strings.TrimRight(frac, "0")
+	number := whole
+	if frac != "" {
+		number += "." + frac
+	}
+	if sign == "-" && number != "0" {
+		number = "-" + number
+	}
+	return number + suffix, nil
+}
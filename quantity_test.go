@@ -0,0 +1,67 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type quantitySuite struct{}
+
+var _ = gc.Suite(&quantitySuite{})
+
+func (s *quantitySuite) TestQuantity(c *gc.C) {
+	sch := schema.Quantity()
+
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"1", "1"},
+		{"500m", "500m"},
+		{"1.5Gi", "1.5Gi"},
+		{"+01.50Gi", "1.5Gi"},
+		{"-0.0", "0"},
+		{"-2k", "-2k"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"1e3", "1e3"},
+		{"12E-2", "12E-2"},
+		{"100Mi", "100Mi"},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+}
+
+func (s *quantitySuite) TestQuantityErrors(c *gc.C) {
+	sch := schema.Quantity()
+
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{"10Gim", `<path>: invalid quantity suffix "Gim" in "10Gim"`},
+		{"10GB", `<path>: invalid quantity suffix "GB" in "10GB"`},
+		{"10 Gi", `<path>: invalid quantity suffix " Gi" in "10 Gi"`},
+		{"1e", `<path>: invalid quantity suffix "e" in "1e"`},
+		{"Gi", `<path>: invalid quantity "Gi"`},
+		{"", `<path>: invalid quantity ""`},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(out, gc.IsNil)
+		c.Check(err.Error(), gc.Equals, test.err)
+	}
+
+	out, err := sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected string, got int(42)`)
+}
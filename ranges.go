@@ -0,0 +1,83 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"sort"
+)
+
+// NonOverlappingRanges returns a Checker that accepts a list whose
+// elements are processed with the elem checker, and describe numeric
+// ranges that must not overlap, such as port ranges. Every coerced
+// element must be a map holding the inclusive bounds of its range under
+// lowField and highField. If two ranges overlap, the error names both
+// elements.
+//
+// The coerced output value has type []interface{}, as with List.
+func NonOverlappingRanges(elem Checker, lowField, highField string) Checker {
+	return nonOverlappingRangesC{elem, lowField, highField}
+}
+
+type nonOverlappingRangesC struct {
+	elem      Checker
+	lowField  string
+	highField string
+}
+
+type numericRange struct {
+	index     int
+	low, high float64
+}
+
+func (c nonOverlappingRangesC) Coerce(v interface{}, path []string) (interface{}, error) {
+	out, err := List(c.elem).Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	elems := out.([]interface{})
+
+	ranges := make([]numericRange, len(elems))
+	for i, elem := range elems {
+		m, ok := asStringMap(elem)
+		if !ok {
+			return nil, CoerceError{Expected: "map", Got: elem, Path: elemPath(path, i)}
+		}
+		low, err := Float().Coerce(m[c.lowField], elemPath(path, i, ".", c.lowField))
+		if err != nil {
+			return nil, err
+		}
+		high, err := Float().Coerce(m[c.highField], elemPath(path, i, ".", c.highField))
+		if err != nil {
+			return nil, err
+		}
+		r := numericRange{i, low.(float64), high.(float64)}
+		if r.low > r.high {
+			return nil, errorf(elemPath(path, i), "%s %v is greater than %s %v", c.lowField, r.low, c.highField, r.high)
+		}
+		ranges[i] = r
+	}
+
+	// Once sorted by their lower bound, a range overlaps an earlier one
+	// exactly when it starts before the furthest end seen so far.
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].low < ranges[j].low
+	})
+	if len(ranges) == 0 {
+		return elems, nil
+	}
+	furthest := ranges[0]
+	for _, r := range ranges[1:] {
+		if r.low <= furthest.high {
+			first, second := furthest, r
+			if first.index > second.index {
+				first, second = second, first
+			}
+			return nil, errorf(path, "range [%d] (%v to %v) overlaps range [%d] (%v to %v)", second.index, second.low, second.high, first.index, first.low, first.high)
+		}
+		if r.high > furthest.high {
+			furthest = r
+		}
+	}
+	return elems, nil
+}
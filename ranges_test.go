@@ -0,0 +1,82 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type rangesSuite struct{}
+
+var _ = gc.Suite(&rangesSuite{})
+
+var portRangeChecker = schema.FieldMap(schema.Fields{
+	"from": schema.Int(),
+	"to":   schema.Int(),
+}, nil)
+
+func portRange(from, to int) map[string]interface{} {
+	return map[string]interface{}{"from": from, "to": to}
+}
+
+func (s *rangesSuite) TestNonOverlappingRanges(c *gc.C) {
+	sch := schema.NonOverlappingRanges(portRangeChecker, "from", "to")
+
+	out, err := sch.Coerce([]interface{}{
+		portRange(8000, 8080),
+		portRange(22, 22),
+		portRange(8081, 9000),
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []interface{}{
+		map[string]interface{}{"from": int64(8000), "to": int64(8080)},
+		map[string]interface{}{"from": int64(22), "to": int64(22)},
+		map[string]interface{}{"from": int64(8081), "to": int64(9000)},
+	})
+
+	out, err = sch.Coerce([]interface{}{}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []interface{}{})
+}
+
+func (s *rangesSuite) TestNonOverlappingRangesErrors(c *gc.C) {
+	sch := schema.NonOverlappingRanges(portRangeChecker, "from", "to")
+
+	tests := []struct {
+		about string
+		in    []interface{}
+		err   string
+	}{{
+		about: "shared bound",
+		in:    []interface{}{portRange(80, 90), portRange(90, 100)},
+		err:   `<path>: range [1] (90 to 100) overlaps range [0] (80 to 90)`,
+	}, {
+		about: "contained range",
+		in:    []interface{}{portRange(50, 60), portRange(1, 1000), portRange(2000, 3000)},
+		err:   `<path>: range [1] (1 to 1000) overlaps range [0] (50 to 60)`,
+	}, {
+		about: "overlap with a range that is not adjacent once sorted",
+		in:    []interface{}{portRange(1, 100), portRange(10, 20), portRange(30, 40)},
+		err:   `<path>: range [1] (10 to 20) overlaps range [0] (1 to 100)`,
+	}, {
+		about: "inverted range",
+		in:    []interface{}{portRange(1, 2), portRange(20, 10)},
+		err:   `<path>[1]: from 20 is greater than to 10`,
+	}, {
+		about: "element error",
+		in:    []interface{}{map[string]interface{}{"from": 1}},
+		err:   `<path>[0].to: expected int, got nothing`,
+	}}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.about)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(out, gc.IsNil)
+		c.Check(err, gc.NotNil)
+		if err != nil {
+			c.Check(err.Error(), gc.Equals, test.err)
+		}
+	}
+}
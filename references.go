@@ -0,0 +1,177 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ListReferences returns a Checker that accepts a list whose elements
+// are processed with the elem checker, and that refer to each other by
+// identifier. Every coerced element must be a map holding a unique
+// identifier under idField, and may hold the identifiers of the
+// elements it refers to under refField, either as a single value or as
+// a list. Processing fails if an element refers to an identifier that
+// doesn't exist, or if the references form a cycle, so that for
+// instance a list of tasks with dependencies is guaranteed to be a
+// directed acyclic graph.
+//
+// The coerced output value has type []interface{}, as with List.
+func ListReferences(elem Checker, idField, refField string) Checker {
+	return listReferencesC{elem, idField, refField}
+}
+
+type listReferencesC struct {
+	elem     Checker
+	idField  string
+	refField string
+}
+
+func (c listReferencesC) Coerce(v interface{}, path []string) (interface{}, error) {
+	out, err := List(c.elem).Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	elems := out.([]interface{})
+
+	ids := make([]interface{}, len(elems))
+	index := make(map[interface{}]int, len(elems))
+	refs := make([][]interface{}, len(elems))
+	for i, elem := range elems {
+		m, ok := asStringMap(elem)
+		if !ok {
+			return nil, CoerceError{Expected: "map", Got: elem, Path: elemPath(path, i)}
+		}
+		id := m[c.idField]
+		if id == nil {
+			return nil, errorf(elemPath(path, i), "missing %s", c.idField)
+		}
+		if !reflect.TypeOf(id).Comparable() {
+			return nil, CoerceError{Expected: "comparable id", Got: id, Path: elemPath(path, i, ".", c.idField)}
+		}
+		if j, ok := index[id]; ok {
+			return nil, errorf(elemPath(path, i, ".", c.idField), "duplicate id %#v (also at [%d])", id, j)
+		}
+		ids[i] = id
+		index[id] = i
+		if rv := reflect.ValueOf(m[c.refField]); rv.Kind() == reflect.Slice {
+			for j := 0; j < rv.Len(); j++ {
+				refs[i] = append(refs[i], rv.Index(j).Interface())
+			}
+		} else if rv.IsValid() {
+			refs[i] = []interface{}{rv.Interface()}
+		}
+	}
+	for i := range elems {
+		for _, ref := range refs[i] {
+			if ref == nil || !reflect.TypeOf(ref).Comparable() {
+				return nil, errorf(elemPath(path, i, ".", c.refField), "invalid reference %#v", ref)
+			}
+			if _, ok := index[ref]; !ok {
+				return nil, errorf(elemPath(path, i, ".", c.refField), "unknown reference %#v", ref)
+			}
+		}
+	}
+
+	// Depth-first search for cycles, tracking the current chain of
+	// references so that it can be reported.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(elems))
+	var chain []int
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		chain = append(chain, i)
+		for _, ref := range refs[i] {
+			j := index[ref]
+			switch state[j] {
+			case visiting:
+				for k, n := range chain {
+					if n == j {
+						return append(append([]int(nil), chain[k:]...), j)
+					}
+				}
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		chain = chain[:len(chain)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range elems {
+		if state[i] != unvisited {
+			continue
+		}
+		if cycle := visit(i); cycle != nil {
+			names := make([]string, len(cycle))
+			for k, n := range cycle {
+				names[k] = fmt.Sprintf("%#v", ids[n])
+			}
+			return nil, errorf(path, "reference cycle %s", strings.Join(names, " -> "))
+		}
+	}
+	return elems, nil
+}
+
+// elemPath returns a new path for the element at index i of the list
+// at path, followed by any extra path elements.
+func elemPath(path []string, i int, extra ...string) []string {
+	p := make([]string, 0, len(path)+3+len(extra))
+	p = append(p, path...)
+	p = append(p, "[", strconv.Itoa(i), "]")
+	return append(p, extra...)
+}
+
+// UniqueField returns a Checker that accepts a list whose elements are
+// processed with the elem checker, and requires the value of field in
+// every coerced element to be unique across the list, as for servers
+// that each need a distinct name. Elements must coerce to maps, and
+// those without the field are not checked.
+//
+// The coerced output value has type []interface{}, as with List.
+func UniqueField(elem Checker, field string) Checker {
+	return uniqueFieldC{elem, field}
+}
+
+type uniqueFieldC struct {
+	elem  Checker
+	field string
+}
+
+func (c uniqueFieldC) Coerce(v interface{}, path []string) (interface{}, error) {
+	out, err := List(c.elem).Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	elems := out.([]interface{})
+	index := make(map[interface{}]int, len(elems))
+	for i, elem := range elems {
+		m, ok := asStringMap(elem)
+		if !ok {
+			return nil, CoerceError{Expected: "map", Got: elem, Path: elemPath(path, i)}
+		}
+		value, ok := m[c.field]
+		if !ok {
+			continue
+		}
+		if value != nil && !reflect.TypeOf(value).Comparable() {
+			return nil, CoerceError{Expected: "comparable value", Got: value, Path: elemPath(path, i, ".", c.field)}
+		}
+		if j, ok := index[value]; ok {
+			return nil, errorf(elemPath(path, i, ".", c.field), "duplicate %s %#v (also at [%d])", c.field, value, j)
+		}
+		index[value] = i
+	}
+	return elems, nil
+}
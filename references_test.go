@@ -0,0 +1,153 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type referencesSuite struct{}
+
+var _ = gc.Suite(&referencesSuite{})
+
+var taskChecker = schema.FieldMap(schema.Fields{
+	"id":        schema.String(),
+	"dependsOn": schema.List(schema.String()),
+}, schema.Defaults{
+	"dependsOn": schema.Omit,
+})
+
+func task(id string, deps ...interface{}) map[string]interface{} {
+	t := map[string]interface{}{"id": id}
+	if len(deps) > 0 {
+		t["dependsOn"] = deps
+	}
+	return t
+}
+
+func (s *referencesSuite) TestListReferences(c *gc.C) {
+	sch := schema.ListReferences(taskChecker, "id", "dependsOn")
+
+	out, err := sch.Coerce([]interface{}{
+		task("build"),
+		task("test", "build"),
+		task("release", "build", "test"),
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.HasLen, 3)
+	c.Assert(out.([]interface{})[1], gc.DeepEquals, map[string]interface{}{
+		"id":        "test",
+		"dependsOn": []interface{}{"build"},
+	})
+
+	// References may be to later elements.
+	_, err = sch.Coerce([]interface{}{
+		task("release", "test"),
+		task("test"),
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *referencesSuite) TestListReferencesSingleValue(c *gc.C) {
+	sch := schema.ListReferences(schema.StringMap(schema.String()), "name", "after")
+
+	_, err := sch.Coerce([]interface{}{
+		map[string]interface{}{"name": "a"},
+		map[string]interface{}{"name": "b", "after": "a"},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+
+	_, err = sch.Coerce([]interface{}{
+		map[string]interface{}{"name": "a", "after": "b"},
+		map[string]interface{}{"name": "b", "after": "a"},
+	}, aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: reference cycle "a" -> "b" -> "a"`)
+}
+
+func (s *referencesSuite) TestListReferencesErrors(c *gc.C) {
+	sch := schema.ListReferences(taskChecker, "id", "dependsOn")
+
+	tests := []struct {
+		about string
+		in    []interface{}
+		err   string
+	}{{
+		about: "dangling reference",
+		in:    []interface{}{task("a"), task("b", "a", "c")},
+		err:   `<path>[1].dependsOn: unknown reference "c"`,
+	}, {
+		about: "duplicate id",
+		in:    []interface{}{task("a"), task("b"), task("a")},
+		err:   `<path>[2].id: duplicate id "a" (also at [0])`,
+	}, {
+		about: "self reference",
+		in:    []interface{}{task("a", "a")},
+		err:   `<path>: reference cycle "a" -> "a"`,
+	}, {
+		about: "longer cycle",
+		in:    []interface{}{task("x"), task("a", "b"), task("b", "c"), task("c", "x", "a")},
+		err:   `<path>: reference cycle "a" -> "b" -> "c" -> "a"`,
+	}, {
+		about: "element error",
+		in:    []interface{}{task("a"), map[string]interface{}{"id": 1}},
+		err:   `<path>[1].id: expected string, got int(1)`,
+	}}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.about)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(out, gc.IsNil)
+		c.Check(err, gc.NotNil)
+		if err != nil {
+			c.Check(err.Error(), gc.Equals, test.err)
+		}
+	}
+
+	sch = schema.ListReferences(schema.Any(), "id", "dependsOn")
+	_, err := sch.Coerce([]interface{}{map[string]interface{}{"name": "a"}}, aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>[0]: missing id`)
+
+	_, err = sch.Coerce([]interface{}{"a"}, aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>[0]: expected map, got string("a")`)
+}
+
+func (s *referencesSuite) TestUniqueField(c *gc.C) {
+	server := schema.FieldMap(schema.Fields{
+		"name": schema.String(),
+		"port": schema.Int(),
+	}, schema.Defaults{
+		"port": schema.Omit,
+	})
+	sch := schema.UniqueField(server, "name")
+
+	v := []interface{}{
+		map[string]interface{}{"name": "web", "port": 80},
+		map[string]interface{}{"name": "db", "port": 80},
+	}
+	out, err := sch.Coerce(v, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []interface{}{
+		map[string]interface{}{"name": "web", "port": int64(80)},
+		map[string]interface{}{"name": "db", "port": int64(80)},
+	})
+
+	v = append(v, map[string]interface{}{"name": "web"})
+	_, err = sch.Coerce(v, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\[2\]\.name: duplicate name "web" \(also at \[0\]\)`)
+
+	// Elements without the field are not checked.
+	out, err = schema.UniqueField(server, "port").Coerce([]interface{}{
+		map[string]interface{}{"name": "a"},
+		map[string]interface{}{"name": "b"},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.HasLen, 2)
+
+	_, err = schema.UniqueField(schema.Any(), "name").Coerce([]interface{}{"a"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\[0\]: expected map, got string\("a"\)`)
+
+	_, err = sch.Coerce([]interface{}{map[string]interface{}{"port": 1}}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\[0\]\.name: expected string, got nothing`)
+}
@@ -4,9 +4,13 @@
 package schema_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
 	gc "gopkg.in/check.v1"
@@ -58,6 +62,49 @@ func (s *S) TestNilSuccess(c *gc.C) {
 
 var nonNilValues = []interface{}{42, "", "foo", false, 3.14, 0}
 
+func (s *S) TestNullable(c *gc.C) {
+	sch := schema.Nullable(schema.Int())
+
+	out, err := sch.Coerce(nil, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.IsNil)
+
+	out, err = sch.Coerce((*int)(nil), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.IsNil)
+
+	out, err = sch.Coerce("42", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, int64(42))
+
+	_, err = sch.Coerce("x", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected int, got string\("x"\)`)
+}
+
+func (s *S) TestNullableInFieldMap(c *gc.C) {
+	sch := schema.FieldMap(schema.Fields{
+		"limit":   schema.Nullable(schema.Int()),
+		"timeout": schema.Nullable(schema.Int()),
+	}, schema.Defaults{
+		"limit":   schema.Omit,
+		"timeout": 30,
+	})
+
+	var v map[string]interface{}
+	err := json.Unmarshal([]byte(`{"limit": null, "timeout": null}`), &v)
+	c.Assert(err, gc.IsNil)
+	out, err := sch.Coerce(v, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"limit": nil, "timeout": nil})
+	data, err := json.Marshal(out)
+	c.Assert(err, gc.IsNil)
+	c.Check(string(data), gc.Equals, `{"limit":null,"timeout":null}`)
+
+	out, err = sch.Coerce(map[string]interface{}{}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"timeout": int64(30)})
+}
+
 func (s *S) TestNilFailuresWithEmptyLabel(c *gc.C) {
 	sch := schema.Nil("")
 	testCheckerFailsForEachBadValueWithErrorPrefix(sch, c, nonNilValues, `<path>: expected empty value`)
@@ -68,6 +115,37 @@ func (s *S) TestNilFailuresWithNonEmptyLabel(c *gc.C) {
 	testCheckerFailsForEachBadValueWithErrorPrefix(sch, c, nonNilValues, `<path>: expected empty wallet`)
 }
 
+func (s *S) TestForbidden(c *gc.C) {
+	sch := schema.Forbidden("use \"size\" instead")
+
+	out, err := sch.Coerce(nil, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.IsNil)
+
+	out, err = sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: field is no longer supported: use "size" instead`)
+
+	fields := schema.FieldMap(schema.Fields{
+		"size":   schema.Int(),
+		"length": schema.Forbidden(`use "size" instead`),
+	}, schema.Defaults{
+		"length": schema.Omit,
+	})
+	out, err = fields.Coerce(map[string]interface{}{"size": 1}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"size": int64(1)})
+
+	out, err = fields.Coerce(map[string]interface{}{"size": 1, "length": 2}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>.length: field is no longer supported: use "size" instead`)
+
+	// An explicit null is rejected too.
+	out, err = fields.Coerce(map[string]interface{}{"size": 1, "length": nil}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>.length: field is no longer supported: use "size" instead`)
+}
+
 func (s *S) TestNonEmptyStringSuccess(c *gc.C) {
 	assertSuccess := func(sch schema.Checker) {
 		out, err := sch.Coerce("non-empty value is ok", aPath)
@@ -94,6 +172,34 @@ func (s *S) TestNonEmptyStringWithNonEmptyLabel(c *gc.C) {
 	testCheckerFailsForEachBadValueWithErrorPrefix(sch, c, badNonEmptyStringValues, `<path>: expected non-empty free beer`)
 }
 
+func (s *S) TestNonBlankString(c *gc.C) {
+	sch := schema.NonBlankString("name")
+	out, err := sch.Coerce("  juju ", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "  juju ")
+
+	testCheckerFailsForEachBadValueWithErrorPrefix(sch, c, []interface{}{42, []string{"x"}, false}, `<path>: expected non-empty name`)
+	for _, v := range []string{"", " ", "\t\n"} {
+		out, err := sch.Coerce(v, aPath)
+		c.Check(out, gc.IsNil)
+		c.Check(err, gc.ErrorMatches, `<path>: empty name not valid`)
+	}
+}
+
+func (s *S) TestTrimmedNonEmptyString(c *gc.C) {
+	sch := schema.TrimmedNonEmptyString("")
+	out, err := sch.Coerce("  juju\n", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "juju")
+
+	testCheckerFailsForEachBadValueWithErrorPrefix(sch, c, []interface{}{42, []string{"x"}, false}, `<path>: expected non-empty string`)
+	for _, v := range []string{"", " ", "\t\n"} {
+		out, err := sch.Coerce(v, aPath)
+		c.Check(out, gc.IsNil)
+		c.Check(err, gc.ErrorMatches, `<path>: empty string not valid`)
+	}
+}
+
 func testCheckerFailsForEachBadValueWithErrorPrefix(sch schema.Checker, c *gc.C, badValues []interface{}, errorPrefix string) {
 	for _, badValue := range badValues {
 		out, err := sch.Coerce(badValue, aPath)
@@ -132,6 +238,190 @@ func (s *S) TestOneOf(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `<path>: unexpected value "bar"`)
 }
 
+func (s *S) TestAllOf(c *gc.C) {
+	sch := schema.AllOf(schema.String(), schema.NonEmptyString("name"))
+
+	out, err := sch.Coerce("app", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "app")
+
+	_, err = sch.Coerce("", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected non-empty name, got string\(""\)`)
+
+	_, err = sch.Coerce(42, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(42\)`)
+
+	// Each checker sees the value coerced by the previous one.
+	sch = schema.AllOf(schema.Int(), schema.Float())
+	out, err = sch.Coerce("12", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, float64(12))
+
+	out, err = schema.AllOf().Coerce("x", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "x")
+}
+
+func (s *S) TestNot(c *gc.C) {
+	sch := schema.Not(schema.Const("default"))
+
+	out, err := sch.Coerce("custom", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "custom")
+
+	_, err = sch.Coerce("default", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: value must not match constraint`)
+
+	_, err = schema.Not(schema.Int()).Coerce(int32(1), nil)
+	c.Check(err, gc.ErrorMatches, `value must not match constraint`)
+
+	// The value is returned unprocessed.
+	out, err = schema.Not(schema.Int()).Coerce("x", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "x")
+
+	out, err = schema.AllOf(schema.String(), schema.Not(schema.Const(""))).Coerce("x", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "x")
+}
+
+func (s *S) TestByType(c *gc.C) {
+	sch := schema.ByType(map[reflect.Kind]schema.Checker{
+		reflect.String: schema.AllOf(schema.String(), schema.Int()),
+		reflect.Map: schema.FieldMap(schema.Fields{
+			"value": schema.Int(),
+		}, nil),
+	})
+
+	out, err := sch.Coerce("42", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, int64(42))
+
+	out, err = sch.Coerce(map[string]interface{}{"value": 1}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"value": int64(1)})
+
+	_, err = sch.Coerce("x", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected int, got string\("x"\)`)
+
+	_, err = sch.Coerce(42, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected map or string, got int\(42\)`)
+
+	_, err = sch.Coerce(nil, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected map or string, got nothing`)
+
+	sch = schema.ByType(map[reflect.Kind]schema.Checker{
+		reflect.Invalid: schema.Const(nil),
+		reflect.Bool:    schema.Bool(),
+	})
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.IsNil)
+	_, err = sch.Coerce(1.5, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected bool or nil, got float64\(1.5\)`)
+}
+
+func (s *S) TestOrSentinel(c *gc.C) {
+	sch := schema.OrSentinel(schema.TimeDuration(), "never", time.Duration(-1))
+
+	out, err := sch.Coerce("never", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, time.Duration(-1))
+
+	out, err = sch.Coerce("5s", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 5*time.Second)
+
+	out, err = sch.Coerce("Never", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: conversion to duration: .*`)
+
+	sch = schema.OrSentinel(schema.Int(), "unlimited", nil)
+	out, err = sch.Coerce("unlimited", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.IsNil)
+
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected int, got nothing`)
+}
+
+func (s *S) TestWithDefault(c *gc.C) {
+	sch := schema.WithDefault(schema.Int(), "8080")
+	out, err := sch.Coerce(nil, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, int64(8080))
+
+	out, err = sch.Coerce(42, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, int64(42))
+
+	_, err = sch.Coerce("x", aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected int, got string\("x"\)`)
+
+	sch = schema.List(schema.WithDefault(schema.String(), "none"))
+	out, err = sch.Coerce([]interface{}{"a", nil}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []interface{}{"a", "none"})
+
+	c.Assert(func() { schema.WithDefault(schema.Int(), "many") }, gc.PanicMatches, `WithDefault got an invalid default: expected int, got string\("many"\)`)
+}
+
+func (s *S) TestOrDefault(c *gc.C) {
+	sch := schema.OrDefault(schema.Int(), "8080")
+	out, err := sch.Coerce(42, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, int64(42))
+
+	for _, in := range []interface{}{"x", nil, true} {
+		out, err = sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, int64(8080))
+	}
+
+	fmap := schema.FieldMap(schema.Fields{
+		"name":    schema.String(),
+		"retries": schema.OrDefault(schema.Int(), 3),
+	}, nil)
+	out, err = fmap.Coerce(map[string]interface{}{"name": "web", "retries": "many"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"name": "web", "retries": int64(3)})
+
+	c.Assert(func() { schema.OrDefault(schema.Int(), "many") }, gc.PanicMatches, `OrDefault got an invalid default: expected int, got string\("many"\)`)
+
+	// Errors from the default are not swallowed.
+	broken := false
+	sch = schema.OrDefault(schema.Transform(schema.String(), func(v interface{}) (interface{}, error) {
+		if broken {
+			return nil, fmt.Errorf("broken")
+		}
+		return v, nil
+	}), "default")
+	broken = true
+	_, err = sch.Coerce(1, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: broken`)
+}
+
+func (s *S) TestTransform(c *gc.C) {
+	sch := schema.Transform(schema.String(), func(v interface{}) (interface{}, error) {
+		if v == "" {
+			return nil, fmt.Errorf("empty name")
+		}
+		return strings.ToUpper(v.(string)), nil
+	})
+	out, err := sch.Coerce("web", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "WEB")
+
+	_, err = sch.Coerce("", aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: empty name`)
+	_, err = sch.Coerce(1, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected string, got int\(1\)`)
+
+	_, err = schema.List(sch).Coerce([]interface{}{"a", ""}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\[1\]: empty name`)
+}
+
 func (s *S) TestBool(c *gc.C) {
 	sch := schema.Bool()
 
@@ -154,6 +444,39 @@ func (s *S) TestBool(c *gc.C) {
 	out, err = sch.Coerce(nil, aPath)
 	c.Assert(out, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, "<path>: expected bool, got nothing")
+
+	for _, value := range []string{"yes", "Yes", "ON", "on"} {
+		out, err := sch.Coerce(value, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, true)
+	}
+	for _, value := range []string{"no", "NO", "off", "Off"} {
+		out, err := sch.Coerce(value, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, false)
+	}
+
+	out, err = sch.Coerce("maybe", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: "maybe" is not a valid boolean`)
+}
+
+func (s *S) TestStrictBool(c *gc.C) {
+	sch := schema.StrictBool()
+
+	for _, value := range []interface{}{true, "true", "1"} {
+		out, err := sch.Coerce(value, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, true)
+	}
+
+	out, err := sch.Coerce("yes", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected bool, got string\("yes"\)`)
+
+	out, err = sch.Coerce(1, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected bool, got int\(1\)`)
 }
 
 func (s *S) TestInt(c *gc.C) {
@@ -362,6 +685,313 @@ func (s *S) TestFloat(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "<path>: expected float, got nothing")
 }
 
+func (s *S) TestJSONNumber(c *gc.C) {
+	out, err := schema.Int().Coerce(json.Number("9007199254740993"), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, int64(9007199254740993))
+
+	out, err = schema.Uint().Coerce(json.Number("42"), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, uint64(42))
+
+	out, err = schema.ForceInt().Coerce(json.Number("4.5"), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 4)
+
+	out, err = schema.ForceUint().Coerce(json.Number("42"), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, uint64(42))
+
+	out, err = schema.Float().Coerce(json.Number("1.5e3"), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 1500.0)
+
+	out, err = schema.NumberRange(0, 1).Coerce(json.Number("0.25"), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 0.25)
+
+	_, err = schema.Int().Coerce(json.Number("1.5"), aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected int, got json.Number\("1.5"\)`)
+	_, err = schema.Float().Coerce(json.Number("x"), aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected float, got json.Number\("x"\)`)
+}
+
+func (s *S) TestIncreasingSequence(c *gc.C) {
+	sch := schema.IncreasingSequence()
+
+	out, err := sch.Coerce([]interface{}{0.1, 1, int64(5), 10.5}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []float64{0.1, 1, 5, 10.5})
+
+	out, err = sch.Coerce([]interface{}{}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []float64{})
+
+	_, err = sch.Coerce([]interface{}{1, 2, 2, 3}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected increasing sequence, got \[1\] 2 followed by \[2\] 2`)
+
+	_, err = sch.Coerce([]interface{}{1, "2"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\[1\]: expected float, got string\("2"\)`)
+
+	_, err = sch.Coerce("1,2", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected list, got string\("1,2"\)`)
+
+	sch = schema.NonDecreasingSequence()
+	out, err = sch.Coerce([]interface{}{1, 2, 2, 3}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []float64{1, 2, 2, 3})
+
+	_, err = sch.Coerce([]interface{}{1, 3, 2}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected non-decreasing sequence, got \[1\] 3 followed by \[2\] 2`)
+}
+
+func (s *S) TestRate(c *gc.C) {
+	sch := schema.Rate()
+
+	tests := []struct {
+		in  string
+		out float64
+	}{
+		{"100/s", 100},
+		{"50/min", 50.0 / 60},
+		{"1800/hour", 0.5},
+		{"0.5/sec", 0.5},
+		{"8640 / day", 0.1},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	_, err := sch.Coerce("100/fortnight", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid rate "100/fortnight": unknown period unit "fortnight"`)
+
+	for _, count := range []string{"-1", "NaN", "inf", "+Inf"} {
+		in := count + "/s"
+		_, err = sch.Coerce(in, aPath)
+		c.Assert(err, gc.NotNil)
+		c.Check(err.Error(), gc.Equals, fmt.Sprintf(`<path>: invalid rate %q: invalid count %q`, in, count))
+	}
+
+	_, err = sch.Coerce("100", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid rate "100": expected count/period`)
+
+	_, err = sch.Coerce(100, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(100\)`)
+}
+
+func (s *S) TestUnitValue(c *gc.C) {
+	sch := schema.UnitValue(map[string]func(float64) interface{}{
+		"ms": func(f float64) interface{} { return time.Duration(f * float64(time.Millisecond)) },
+		"s":  func(f float64) interface{} { return time.Duration(f * float64(time.Second)) },
+		"KB": func(f float64) interface{} { return int64(f * 1000) },
+	})
+
+	tests := []struct {
+		in  string
+		out interface{}
+	}{
+		{"1.5s", 1500 * time.Millisecond},
+		{"250 ms", 250 * time.Millisecond},
+		{"2KB", int64(2000)},
+		{"1e1s", 10 * time.Second},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	_, err := sch.Coerce("5m", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: unknown unit "m" in "5m", expected one of \["KB" "ms" "s"\]`)
+
+	_, err = sch.Coerce("5", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: unknown unit "" in "5", expected one of \["KB" "ms" "s"\]`)
+
+	_, err = sch.Coerce("fast", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid value "fast": expected number followed by unit`)
+
+	_, err = sch.Coerce(5, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(5\)`)
+
+	sch = schema.UnitValue(map[string]func(float64) interface{}{
+		"": func(f float64) interface{} { return f },
+	})
+	out, err := sch.Coerce("5", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, 5.0)
+}
+
+func (s *S) TestNumberRange(c *gc.C) {
+	sch := schema.NumberRange(1, 3)
+	for _, in := range []interface{}{1, int64(2), 2.5, "3", uint8(3)} {
+		_, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+	}
+	out, err := sch.Coerce("2.5", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 2.5)
+
+	_, err = sch.Coerce(5, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: 5 is not in the range [1, 3]")
+	_, err = sch.Coerce("0.5", aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: 0.5 is not in the range [1, 3]")
+	_, err = sch.Coerce("NaN", aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: NaN is not in the range [1, 3]")
+	_, err = sch.Coerce("two", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected number, got string("two")`)
+	_, err = sch.Coerce(nil, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected number, got nothing")
+
+	sch = schema.NumberRange(math.Inf(-1), 0)
+	_, err = sch.Coerce(1, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: 1 is greater than the maximum 0")
+}
+
+func (s *S) TestMinMax(c *gc.C) {
+	out, err := schema.Min(1).Coerce(1e10, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 1e10)
+	_, err = schema.Min(1).Coerce(0, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: 0 is less than the minimum 1")
+
+	out, err = schema.Max(1).Coerce("-5", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, -5.0)
+	_, err = schema.Max(1).Coerce(1.5, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: 1.5 is greater than the maximum 1")
+}
+
+func (s *S) TestIntRange(c *gc.C) {
+	sch := schema.IntRange(1, 3)
+	out, err := sch.Coerce("2", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 2)
+	out, err = sch.Coerce(int64(3), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 3)
+
+	_, err = sch.Coerce(5, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: 5 is not in the range [1, 3]")
+	_, err = sch.Coerce(2.5, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected int, got float64(2.5)")
+}
+
+func (s *S) TestLocalizedFloat(c *gc.C) {
+	sch := schema.LocalizedFloat(',', '.')
+
+	tests := []struct {
+		in  interface{}
+		out float64
+	}{
+		{"3,14", 3.14},
+		{"-0,5", -0.5},
+		{"42", 42},
+		{"1.234.567,89", 1234567.89},
+		{"999,5", 999.5},
+		{1.5, 1.5},
+		{int64(7), 7},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %v", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	errTests := []struct {
+		in  interface{}
+		err string
+	}{
+		{"3,1,4", `<path>: invalid number "3,1,4": unexpected character ','`},
+		{"1.23,5", `<path>: invalid number "1.23,5": misplaced thousands separator`},
+		{"1234.567", `<path>: invalid number "1234.567": misplaced thousands separator`},
+		{"12.34", `<path>: invalid number "12.34": misplaced thousands separator`},
+		{".123", `<path>: invalid number ".123": misplaced thousands separator`},
+		{"3e5", `<path>: invalid number "3e5": unexpected character 'e'`},
+		{"-", `<path>: invalid number "-": no digits`},
+		{true, `<path>: expected float, got bool\(true\)`},
+	}
+	for i, test := range errTests {
+		c.Logf("test %d: %v", i, test.in)
+		_, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+
+	// Without a thousands separator, a dot is not allowed at all.
+	_, err := schema.LocalizedFloat(',').Coerce("1.234", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid number "1.234": unexpected character '.'`)
+
+	c.Check(func() { schema.LocalizedFloat(',', ',') }, gc.PanicMatches, "LocalizedFloat got the same decimal and thousands separators")
+}
+
+func (s *S) TestTypedQuantity(c *gc.C) {
+	sch := schema.TypedQuantity(map[string]float64{
+		"Hz":  1,
+		"kHz": 1e3,
+		"MHz": 1e6,
+		"GHz": 1e9,
+	}, "Hz")
+
+	tests := []struct {
+		in  string
+		out float64
+	}{
+		{"50Hz", 50},
+		{"2.4GHz", 2.4e9},
+		{"100 kHz", 1e5},
+		{"0.5MHz", 5e5},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	_, err := sch.Coerce("5THz", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: unknown unit "THz" in "5THz", expected one of \["GHz" "Hz" "MHz" "kHz"\]`)
+
+	c.Check(func() {
+		schema.TypedQuantity(map[string]float64{"C": 1, "mC": 1e-3}, "K")
+	}, gc.PanicMatches, `TypedQuantity got base unit "K" without a factor of 1`)
+}
+
+func (s *S) TestQuantiles(c *gc.C) {
+	sch := schema.Quantiles()
+
+	out, err := sch.Coerce("0.99, 0.5,0.9", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []float64{0.5, 0.9, 0.99})
+
+	out, err = sch.Coerce([]interface{}{0.9, float32(0.25)}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []float64{0.25, 0.9})
+
+	out, err = sch.Coerce("0.5,1", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: quantile 1 not in range (0, 1)")
+
+	out, err = sch.Coerce("0.5,0.9,0.5", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: duplicate quantile 0.5")
+
+	out, err = sch.Coerce("0.5,high", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: invalid quantile "high"`)
+
+	out, err = sch.Coerce([]interface{}{true}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>[0]: expected float, got bool(true)")
+
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected string or list, got nothing")
+}
+
 func (s *S) TestString(c *gc.C) {
 	sch := schema.String()
 
@@ -391,6 +1021,14 @@ func (s *S) TestURL(c *gc.C) {
 	expected := mustParse("foo")
 	c.Assert(*(out.(*url.URL)), gc.Equals, *expected)
 
+	// Without schemes, relative URLs are accepted and hosts are kept
+	// as they are.
+	for _, in := range []string{"/path", "http://Example.COM/"} {
+		out, err = sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out.(*url.URL).String(), gc.Equals, in)
+	}
+
 	out, err = sch.Coerce(true, aPath)
 	c.Assert(out, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, `<path>: expected url string, got bool\(true\)`)
@@ -404,23 +1042,269 @@ func (s *S) TestURL(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `<path>: expected valid url, got string\(":::"\)`)
 }
 
-func (s *S) TestSimpleRegexp(c *gc.C) {
-	sch := schema.SimpleRegexp()
-	out, err := sch.Coerce("[0-9]+", aPath)
+func (s *S) TestURLSchemes(c *gc.C) {
+	sch := schema.URL("http", "https")
+
+	out, err := sch.Coerce("HTTPS://Example.COM/Path?q=1", aPath)
 	c.Assert(err, gc.IsNil)
-	c.Assert(out, gc.Equals, "[0-9]+")
+	c.Check(out.(*url.URL).String(), gc.Equals, "https://example.com/Path?q=1")
+
+	tests := []struct {
+		in  interface{}
+		err string
+	}{
+		{"ftp://example.com", `<path>: invalid URL "ftp://example.com": scheme "ftp" not allowed, expected one of \["http" "https"\]`},
+		{"example.com/path", `<path>: invalid URL "example.com/path": missing scheme`},
+		{"http:///path", `<path>: invalid URL "http:///path": missing host`},
+		{":::", `<path>: expected valid url, got string\(":::"\)`},
+		{42, `<path>: expected url string, got int\(42\)`},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %v", i, test.in)
+		_, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
 
-	out, err = sch.Coerce(1, aPath)
-	c.Assert(out, gc.IsNil)
-	c.Assert(err, gc.ErrorMatches, `<path>: expected regexp string, got int\(1\)`)
+func (s *S) TestURLString(c *gc.C) {
+	out, err := schema.URLString().Coerce("foo", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "foo")
 
-	out, err = sch.Coerce("[", aPath)
-	c.Assert(out, gc.IsNil)
-	c.Assert(err, gc.ErrorMatches, `<path>: expected valid regexp, got string\("\["\)`)
+	sch := schema.URLString("https")
+	out, err = sch.Coerce("https://Example.com:8443/a b", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "https://example.com:8443/a%20b")
 
-	out, err = sch.Coerce(nil, aPath)
-	c.Assert(out, gc.IsNil)
-	c.Assert(err, gc.ErrorMatches, `<path>: expected regexp string, got nothing`)
+	_, err = sch.Coerce("http://example.com", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid URL "http://example.com": scheme "http" not allowed, expected one of \["https"\]`)
+}
+
+func (s *S) TestTemplateResolvable(c *gc.C) {
+	sch := schema.TemplateResolvable(map[string]interface{}{
+		"name": "app",
+		"unit": map[string]interface{}{"id": 0},
+	})
+
+	out, err := sch.Coerce("{{.name}}/{{.unit.id}}", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "{{.name}}/{{.unit.id}}")
+
+	_, err = sch.Coerce("{{.name}}-{{.model}}", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: template does not resolve: .*map has no entry for key "model"`)
+
+	_, err = sch.Coerce("{{.unit.name}}", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: template does not resolve: .*map has no entry for key "name"`)
+
+	_, err = sch.Coerce("{{.name", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid template: .*unclosed action`)
+
+	_, err = sch.Coerce(1, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(1\)`)
+}
+
+func (s *S) TestSimpleRegexp(c *gc.C) {
+	sch := schema.SimpleRegexp()
+	out, err := sch.Coerce("[0-9]+", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "[0-9]+")
+
+	out, err = sch.Coerce(1, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected regexp string, got int\(1\)`)
+
+	out, err = sch.Coerce("[", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected valid regexp, got string\("\["\)`)
+
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected regexp string, got nothing`)
+}
+
+func (s *S) TestRegexpPattern(c *gc.C) {
+	sch := schema.RegexpPattern()
+	out, err := sch.Coerce("^[0-9]+$", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.FitsTypeOf, &regexp.Regexp{})
+	c.Assert(out.(*regexp.Regexp).String(), gc.Equals, "^[0-9]+$")
+
+	out, err = sch.Coerce("a(b", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: conversion to regexp: error parsing regexp: missing closing ): `a(b`")
+
+	out, err = sch.Coerce(1, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected regexp string, got int\(1\)`)
+
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected regexp string, got nothing`)
+}
+
+func (s *S) TestMatch(c *gc.C) {
+	sch := schema.Match("^[0-9]+$")
+	out, err := sch.Coerce("123", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "123")
+
+	out, err = sch.Coerce("abc", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: value "abc" does not match pattern "^[0-9]+$"`)
+
+	out, err = sch.Coerce(123, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected string, got int\(123\)`)
+
+	c.Assert(func() { schema.Match("a(b") }, gc.PanicMatches, "Match got an invalid pattern: error parsing regexp: missing closing \\): `a\\(b`")
+
+	sch = schema.MatchRegexp(regexp.MustCompile(`^[a-z][a-z0-9-]*$`))
+	out, err = sch.Coerce("my-slug", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "my-slug")
+	_, err = sch.Coerce("My Slug", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: value "My Slug" does not match pattern "^[a-z][a-z0-9-]*$"`)
+}
+
+func (s *S) TestFilter(c *gc.C) {
+	sch := schema.Filter([]string{"node_*", "go_*"}, []string{"go_gc_*", "node_cpu"})
+	for _, in := range []string{"node_memory", "go_threads"} {
+		out, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, in)
+	}
+
+	_, err := sch.Coerce("process_cpu", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: value "process_cpu" does not match any of the include patterns ["node_*" "go_*"]`)
+	_, err = sch.Coerce("go_gc_duration", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: value "go_gc_duration" is excluded by pattern "go_gc_*"`)
+	_, err = sch.Coerce("node_cpu", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: value "node_cpu" is excluded by pattern "node_cpu"`)
+	_, err = sch.Coerce(nil, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected string, got nothing`)
+
+	// Without include patterns, anything not excluded is accepted.
+	sch = schema.Filter(nil, []string{"tmp*"})
+	out, err := sch.Coerce("data", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "data")
+	_, err = sch.Coerce("tmp1", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: value "tmp1" is excluded by pattern "tmp*"`)
+
+	c.Assert(func() { schema.Filter(nil, []string{"a["}) }, gc.PanicMatches, `Filter got an invalid pattern "a\[": syntax error in pattern`)
+}
+
+func (s *S) TestGlob(c *gc.C) {
+	sch := schema.Glob("web-*", "db-[0-9]", "cache")
+	for _, in := range []string{"web-1", "web-", "db-3", "cache"} {
+		out, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, in)
+	}
+
+	for _, in := range []string{"db-10", "web/1", "caches"} {
+		_, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.NotNil)
+		c.Assert(err.Error(), gc.Equals, fmt.Sprintf(`<path>: value %q does not match any of the patterns ["web-*" "db-[0-9]" "cache"]`, in))
+	}
+	_, err := sch.Coerce(42, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected string, got int\(42\)`)
+
+	c.Assert(func() { schema.Glob() }, gc.PanicMatches, "Glob got no patterns")
+	c.Assert(func() { schema.Glob("ok", "a[") }, gc.PanicMatches, `Glob got an invalid pattern "a\[": syntax error in pattern`)
+}
+
+func (s *S) TestEnum(c *gc.C) {
+	sch := schema.Enum("green", "blue")
+	out, err := sch.Coerce("blue", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "blue")
+
+	_, err = sch.Coerce("red", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: "red" is not one of ["green" "blue"]`)
+	_, err = sch.Coerce("Blue", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: "Blue" is not one of ["green" "blue"]`)
+	_, err = sch.Coerce(1, aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected string, got int(1)`)
+
+	sch = schema.EnumFold("TCP", "UDP")
+	for _, in := range []string{"tcp", "Tcp", "TCP"} {
+		out, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, "TCP")
+	}
+	_, err = sch.Coerce("sctp", aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: "sctp" is not one of ["TCP" "UDP"]`)
+}
+
+func (s *S) TestStringLen(c *gc.C) {
+	sch := schema.StringLen(3, 64)
+	for _, in := range []string{"abc", "héllo", strings.Repeat("x", 64)} {
+		out, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, in)
+	}
+
+	_, err := sch.Coerce("ab", aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: string length 2 not in range [3, 64]")
+	_, err = sch.Coerce("日本", aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: string length 2 not in range [3, 64]")
+	_, err = sch.Coerce(strings.Repeat("x", 65), aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: string length 65 not in range [3, 64]")
+	_, err = sch.Coerce(123, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected string, got int(123)")
+
+	sch = schema.StringLen(1, -1)
+	_, err = sch.Coerce(strings.Repeat("x", 1000), aPath)
+	c.Assert(err, gc.IsNil)
+	_, err = sch.Coerce("", aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>: string length 0 is less than the minimum 1")
+}
+
+func (s *S) TestSelector(c *gc.C) {
+	parse := func(s string) error {
+		if strings.Count(s, "[") != strings.Count(s, "]") {
+			return fmt.Errorf("unbalanced brackets")
+		}
+		return nil
+	}
+	sch := schema.Selector(parse)
+
+	out, err := sch.Coerce("div.item[data-id]", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "div.item[data-id]")
+
+	out, err = sch.Coerce("div[data-id", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: invalid selector "div[data-id": unbalanced brackets`)
+
+	out, err = sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected string, got int(42)`)
+}
+
+func (s *S) TestTagOptions(c *gc.C) {
+	sch := schema.TagOptions("omitempty", "required", "inline")
+
+	out, err := sch.Coerce("omitempty, required,omitempty", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []string{"omitempty", "required"})
+
+	out, err = sch.Coerce("", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []string{})
+
+	out, err = sch.Coerce("omitempty,flow", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: unknown option "flow", expected one of ["omitempty" "required" "inline"]`)
+
+	out, err = sch.Coerce("omitempty,,inline", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: empty option in "omitempty,,inline"`)
+
+	out, err = sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected string, got int(42)`)
 }
 
 func (s *S) TestList(c *gc.C) {
@@ -440,6 +1324,135 @@ func (s *S) TestList(c *gc.C) {
 	out, err = sch.Coerce([]interface{}{1, true}, aPath)
 	c.Assert(out, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, `<path>\[1\]: expected int, got bool\(true\)`)
+
+	out, err = sch.Coerce([3]string{"1", "2", "3"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []interface{}{int64(1), int64(2), int64(3)})
+
+	out, err = sch.Coerce([2]interface{}{1, "x"}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>\[1\]: expected int, got string\("x"\)`)
+}
+
+func (s *S) TestListLen(c *gc.C) {
+	sch := schema.ListLen(schema.String(), 1, 2)
+	out, err := sch.Coerce([]string{"a", "b"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []interface{}{"a", "b"})
+
+	_, err = sch.Coerce([]string{}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: list length 0 not in range \[1, 2\]`)
+	_, err = sch.Coerce([]string{"a", "b", "c"}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: list length 3 not in range \[1, 2\]`)
+	_, err = sch.Coerce([]interface{}{1}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\[0\]: expected string, got int\(1\)`)
+
+	sch = schema.ListLen(schema.String(), 1, -1)
+	out, err = sch.Coerce(make([]string, 100), aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.HasLen, 100)
+	_, err = sch.Coerce(nil, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected list, got nothing`)
+	_, err = sch.Coerce([]string{}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: list length 0 is less than the minimum 1`)
+}
+
+func (s *S) TestTuple(c *gc.C) {
+	sch := schema.Tuple(schema.String(), schema.Port(), schema.Float())
+	out, err := sch.Coerce([]interface{}{"web", "8080", 0.5}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []interface{}{"web", 8080, 0.5})
+
+	_, err = sch.Coerce([]interface{}{"web", 8080}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected list of 3 elements, got 2`)
+	_, err = sch.Coerce([]interface{}{"web", "http", 1}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\[1\]: invalid port "http"`)
+	_, err = sch.Coerce("web", aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected list, got string\("web"\)`)
+
+	out, err = schema.Tuple().Coerce([0]int{}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []interface{}{})
+}
+
+func (s *S) TestListOrScalar(c *gc.C) {
+	sch := schema.ListOrScalar(schema.String())
+
+	out, err := sch.Coerce("a", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []interface{}{"a"})
+
+	out, err = sch.Coerce([]string{"a", "b"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []interface{}{"a", "b"})
+
+	out, err = sch.Coerce([]interface{}{}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []interface{}{})
+
+	_, err = sch.Coerce(42, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\[0\]: expected string, got int\(42\)`)
+
+	_, err = sch.Coerce([]interface{}{"a", 42}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\[1\]: expected string, got int\(42\)`)
+}
+
+func (s *S) TestDedupeStrings(c *gc.C) {
+	sch := schema.DedupeStrings()
+
+	out, err := sch.Coerce([]interface{}{"b", "a", "b", "c", "a"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []string{"b", "a", "c"})
+
+	out, err = sch.Coerce([]string{}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []string{})
+
+	_, err = sch.Coerce([]interface{}{"a", 1}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\[1\]: expected string, got int\(1\)`)
+
+	_, err = sch.Coerce("a", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected list, got string\("a"\)`)
+}
+
+func (s *S) TestOrderedMap(c *gc.C) {
+	sch := schema.OrderedMap(schema.Int())
+	out, err := sch.Coerce([]interface{}{
+		map[string]interface{}{"b": 1},
+		map[interface{}]interface{}{"a": "2"},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []schema.KeyValue{
+		{Key: "b", Value: int64(1)},
+		{Key: "a", Value: int64(2)},
+	})
+
+	out, err = sch.Coerce([]interface{}{
+		map[string]interface{}{"b": 1},
+		map[string]interface{}{"b": 2},
+	}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>\[1\]: duplicate key "b"`)
+
+	out, err = sch.Coerce([]interface{}{
+		map[string]interface{}{"a": 1, "b": 2},
+	}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>\[0\]: expected single-entry map, got .*`)
+
+	out, err = sch.Coerce([]interface{}{"a"}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>\[0\]: expected single-entry map, got string\("a"\)`)
+
+	out, err = sch.Coerce([]interface{}{
+		map[string]interface{}{"a": true},
+	}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>\[0\]\.a: expected int, got bool\(true\)`)
+
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "<path>: expected list, got nothing")
 }
 
 func (s *S) TestMap(c *gc.C) {
@@ -491,7 +1504,15 @@ func (s *S) TestStringMap(c *gc.C) {
 
 	out, err = sch.Coerce(map[int]int{1: 1}, aPath)
 	c.Assert(out, gc.IsNil)
-	c.Assert(err, gc.ErrorMatches, "<path>: expected string, got int\\(1\\)")
+	c.Assert(err, gc.ErrorMatches, `<path>: expected map\[string\], got map\[int\]int\(map\[int\]int{1:1}\)`)
+
+	out, err = sch.Coerce(map[interface{}]interface{}{"a": 1, 2: 2}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected map\[string\], got .*`)
+
+	out, err = sch.Coerce(map[interface{}]interface{}{"a": 1, "b": "2"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"a": int64(1), "b": int64(2)})
 
 	out, err = sch.Coerce(map[string]bool{"a": true}, aPath)
 	c.Assert(out, gc.IsNil)
@@ -503,6 +1524,29 @@ func (s *S) TestStringMap(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `a: expected int, got bool\(true\)`)
 }
 
+func (s *S) TestMapInvariant(c *gc.C) {
+	sch := schema.MapInvariant(schema.Int(), func(m map[string]interface{}) error {
+		var total int64
+		for _, w := range m {
+			total += w.(int64)
+		}
+		if total != 100 {
+			return fmt.Errorf("weights sum to %d, expected 100", total)
+		}
+		return nil
+	})
+
+	out, err := sch.Coerce(map[string]interface{}{"a": 60, "b": "40"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"a": int64(60), "b": int64(40)})
+
+	_, err = sch.Coerce(map[string]interface{}{"a": 60, "b": 30}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: weights sum to 90, expected 100`)
+
+	_, err = sch.Coerce(map[string]interface{}{"a": true}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.a: expected int, got bool\(true\)`)
+}
+
 func assertFieldMap(c *gc.C, sch schema.Checker) {
 	out, err := sch.Coerce(map[string]interface{}{"a": "A", "b": "B"}, aPath)
 
@@ -583,6 +1627,9 @@ func (s *S) TestFieldMapInterfaceKey(c *gc.C) {
 
 	_, err = sch.Coerce(map[interface{}]interface{}{1: "A"}, aPath)
 	c.Check(err, gc.ErrorMatches, `<path>: expected map\[string], got map\[interface {}]interface {}\(map\[interface {}]interface {}{1:"A"}\)`)
+
+	_, err = sch.Coerce(map[interface{}]interface{}{nil: "A"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected map\[string], got .*`)
 }
 
 func (s *S) TestFieldMapDefaultInvalid(c *gc.C) {
@@ -617,6 +1664,538 @@ func (s *S) TestStrictFieldMap(c *gc.C) {
 	out, err = sch.Coerce(map[string]interface{}{"a": "A", "b": "B", "d": "D"}, nil)
 	c.Assert(out, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, `unknown key "d" \(value "D"\)`)
+
+	out, err = sch.Coerce(map[string]interface{}{"a": "A", "foo": 1, "bar": 2, "d": "D"}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: unknown keys ["bar" "d" "foo"]`)
+
+	_, err = schema.CoerceAll(sch, map[string]interface{}{"a": "X", "foo": 1, "bar": 2}, aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: unknown keys ["bar" "foo"]; <path>.a: expected "A", got string("X")`)
+}
+
+func (s *S) TestRename(c *gc.C) {
+	sch := schema.Rename(map[string]string{"old_name": "new_name", "db_host": "host"}, schema.StrictFieldMap(schema.Fields{
+		"new_name": schema.String(),
+		"host":     schema.String(),
+	}, schema.Defaults{
+		"host": "localhost",
+	}))
+
+	out, err := sch.Coerce(map[string]interface{}{"old_name": "a", "db_host": "db"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"new_name": "a", "host": "db"})
+
+	out, err = sch.Coerce(map[interface{}]interface{}{"new_name": "a"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"new_name": "a", "host": "localhost"})
+
+	_, err = sch.Coerce(map[string]interface{}{"old_name": 1}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\.new_name: expected string, got int\(1\)`)
+
+	_, err = sch.Coerce(map[string]interface{}{"old_name": "a", "new_name": "b"}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: both "old_name" and its new name "new_name" are set`)
+
+	_, err = sch.Coerce([]string{}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected map, got \[\]string\(\[\]string{}\)`)
+	_, err = sch.Coerce(map[int]string{}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected map\[string\], got .*`)
+
+	// The input map is left untouched.
+	in := map[string]interface{}{"old_name": "a"}
+	_, err = sch.Coerce(in, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(in, gc.DeepEquals, map[string]interface{}{"old_name": "a"})
+}
+
+func (s *S) TestStrictFieldMapInterfaceKey(c *gc.C) {
+	sch := schema.StrictFieldMap(schema.Fields{
+		"a": schema.Const("A"),
+	}, nil)
+
+	out, err := sch.Coerce(map[interface{}]interface{}{"a": "A"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, map[string]interface{}{"a": "A"})
+
+	_, err = sch.Coerce(map[interface{}]interface{}{"a": "A", "b": "B"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: unknown key "b" \(value "B"\)`)
+}
+
+func (s *S) TestCaseInsensitiveKeys(c *gc.C) {
+	fields := schema.Fields{
+		"logLevel": schema.String(),
+		"port":     schema.Int(),
+	}
+	sch := schema.CaseInsensitiveKeys(schema.FieldMap(fields, schema.Defaults{"port": 80}))
+
+	out, err := sch.Coerce(map[string]interface{}{"LOGLEVEL": "debug", "Port": 8080, "other": true}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"logLevel": "debug", "port": int64(8080)})
+
+	out, err = sch.Coerce(map[interface{}]interface{}{"loglevel": "info"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"logLevel": "info", "port": int64(80)})
+
+	_, err = sch.Coerce(map[string]interface{}{"loglevel": "a", "PORT": "x"}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\.port: expected int, got string\("x"\)`)
+
+	_, err = sch.Coerce(map[string]interface{}{"logLevel": "debug", "LogLevel": "info"}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: keys "LogLevel" and "logLevel" differ only in case`)
+
+	strict := schema.CaseInsensitiveKeys(schema.StrictFieldMap(fields, schema.Defaults{"port": schema.Omit}))
+	out, err = strict.Coerce(map[string]interface{}{"LogLevel": "debug"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"logLevel": "debug"})
+	_, err = strict.Coerce(map[string]interface{}{"LogLevel": "debug", "Other": 1}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: unknown key "Other" \(value 1\)`)
+
+	// Without the wrapper, keys are matched exactly.
+	_, err = schema.FieldMap(fields, nil).Coerce(map[string]interface{}{"LOGLEVEL": "debug", "port": 1}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\.logLevel: expected string, got nothing`)
+}
+
+func (s *S) TestCaseInsensitiveKeysPanics(c *gc.C) {
+	c.Assert(func() { schema.CaseInsensitiveKeys(schema.String()) }, gc.PanicMatches, "CaseInsensitiveKeys got a non-FieldMap checker")
+	c.Assert(func() {
+		schema.CaseInsensitiveKeys(schema.FieldMap(schema.Fields{"a": schema.Int(), "A": schema.Int()}, nil))
+	}, gc.PanicMatches, `CaseInsensitiveKeys got fields "A" and "a" that differ only in case`)
+}
+
+func (s *S) TestPreserveUnknown(c *gc.C) {
+	sch := schema.PreserveUnknown(schema.FieldMap(schema.Fields{
+		"a": schema.Int(),
+		"b": schema.String(),
+	}, schema.Defaults{
+		"b": "B",
+	}))
+
+	out, err := sch.Coerce(map[string]interface{}{"a": "1", "x": "X", "y": []int{1}}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{
+		"a": int64(1),
+		"b": "B",
+		"x": "X",
+		"y": []int{1},
+	})
+
+	out, err = sch.Coerce(map[interface{}]interface{}{"a": 1, "x": "X"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"a": int64(1), "b": "B", "x": "X"})
+
+	out, err = sch.Coerce(map[string]interface{}{"a": true, "x": "X"}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>\.a: expected int, got bool\(true\)`)
+
+	c.Assert(func() {
+		schema.PreserveUnknown(schema.StrictFieldMap(nil, nil))
+	}, gc.PanicMatches, "PreserveUnknown got a StrictFieldMap")
+	c.Assert(func() {
+		schema.PreserveUnknown(schema.Any())
+	}, gc.PanicMatches, "PreserveUnknown got a non-FieldMap checker")
+}
+
+func (s *S) TestNestedErrorPaths(c *gc.C) {
+	server := schema.FieldMap(schema.Fields{
+		"port": schema.Int(),
+	}, nil)
+	sch := schema.FieldMap(schema.Fields{
+		"servers": schema.List(server),
+		"groups":  schema.StringMap(schema.List(schema.String())),
+		"matrix":  schema.List(schema.List(schema.Int())),
+	}, schema.Defaults{
+		"servers": schema.Omit,
+		"groups":  schema.Omit,
+		"matrix":  schema.Omit,
+	})
+
+	tests := []struct {
+		path []string
+		v    map[string]interface{}
+		err  string
+	}{{
+		v: map[string]interface{}{
+			"servers": []interface{}{
+				map[string]interface{}{"port": 1},
+				map[string]interface{}{"port": 2},
+				map[string]interface{}{"port": "x"},
+			},
+		},
+		err: `servers\[2\]\.port: expected int, got string\("x"\)`,
+	}, {
+		v: map[string]interface{}{
+			"groups": map[string]interface{}{"admins": []interface{}{"bob", 42}},
+		},
+		err: `groups\.admins\[1\]: expected string, got int\(42\)`,
+	}, {
+		v: map[string]interface{}{
+			"matrix": []interface{}{[]interface{}{1}, []interface{}{2, "three"}},
+		},
+		err: `matrix\[1\]\[1\]: expected int, got string\("three"\)`,
+	}, {
+		path: []string{"config"},
+		v: map[string]interface{}{
+			"servers": []interface{}{map[string]interface{}{}},
+		},
+		err: `config\.servers\[0\]\.port: expected int, got nothing`,
+	}}
+	for i, test := range tests {
+		c.Logf("test %d", i)
+		_, err := sch.Coerce(test.v, test.path)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+
+	_, err := schema.List(server).Coerce([]interface{}{"x"}, nil)
+	c.Check(err, gc.ErrorMatches, `\[0\]: expected map, got string\("x"\)`)
+}
+
+func (s *S) TestEnvVarName(c *gc.C) {
+	sch := schema.EnvVarName()
+
+	for _, name := range []string{"PATH", "_", "my_var2", "JUJU_DATA"} {
+		out, err := sch.Coerce(name, aPath)
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.Equals, name)
+	}
+
+	_, err := sch.Coerce("2FAST", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid environment variable name "2FAST": unexpected character '2'`)
+
+	_, err = sch.Coerce("MY-VAR", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid environment variable name "MY-VAR": unexpected character '-'`)
+
+	_, err = sch.Coerce("", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: empty environment variable name`)
+
+	_, err = sch.Coerce(42, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(42\)`)
+}
+
+func (s *S) TestCoerceOrdered(c *gc.C) {
+	sch := schema.FieldMap(schema.Fields{
+		"b":    schema.Int(),
+		"a":    schema.String(),
+		"c":    schema.StringMap(schema.Any()),
+		"none": schema.Int(),
+	}, schema.Defaults{
+		"b":    1,
+		"none": schema.Omit,
+	})
+
+	out, err := schema.CoerceOrdered(sch, map[string]interface{}{
+		"a": "x",
+		"c": map[string]interface{}{"z": 1},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.DeepEquals, []schema.KeyValue{
+		{"a", "x"},
+		{"b", int64(1)},
+		{"c", map[string]interface{}{"z": 1}},
+	})
+
+	_, err = schema.CoerceOrdered(sch, map[string]interface{}{"a": 1, "c": map[string]interface{}{}}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.a: expected string, got int\(1\)`)
+
+	_, err = schema.CoerceOrdered(schema.List(schema.Any()), []interface{}{}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected map\[string\], got \[\]interface {}\(\[\]interface {}{}\)`)
+}
+
+func (s *S) TestCoerceAll(c *gc.C) {
+	db := schema.StrictFieldMap(schema.Fields{
+		"host": schema.String(),
+		"port": schema.Int(),
+	}, schema.Defaults{
+		"port": "not a port",
+	})
+	sch := schema.Constrained(schema.StrictFieldMap(schema.Fields{
+		"name":    schema.String(),
+		"replica": schema.Int(),
+		"db":      db,
+		"tags":    schema.List(schema.String()),
+		"pools":   schema.StringMap(schema.Any()),
+		"pool":    schema.String(),
+	}, nil), schema.RefersToKeysOf("pool", "pools"))
+
+	_, err := schema.CoerceAll(sch, map[string]interface{}{
+		"name":    1,
+		"replica": "x",
+		"db":      map[string]interface{}{"host": true, "user": "bob"},
+		"tags":    []interface{}{"a", 2, 3},
+		"pools":   map[string]interface{}{"a": 1},
+		"pool":    "a",
+		"extra":   true,
+	}, aPath)
+	c.Assert(err, gc.FitsTypeOf, &schema.ErrorList{})
+	var msgs []string
+	for _, err := range err.(*schema.ErrorList).Errors() {
+		msgs = append(msgs, err.Error())
+	}
+	c.Check(msgs, gc.DeepEquals, []string{
+		`<path>: unknown key "extra" (value true)`,
+		`<path>.db: unknown key "user" (value "bob")`,
+		`<path>.db.host: expected string, got bool(true)`,
+		`<path>.db.port: expected int, got string("not a port")`,
+		`<path>.name: expected string, got int(1)`,
+		`<path>.replica: expected int, got string("x")`,
+		`<path>.tags[1]: expected string, got int(2)`,
+	})
+	c.Check(err, gc.ErrorMatches, `<path>: unknown key "extra" \(value true\); <path>\.db: .*; <path>\.tags\[1\]: expected string, got int\(2\)`)
+
+	// Constraints are checked once the fields are valid.
+	_, err = schema.CoerceAll(sch, map[string]interface{}{
+		"name":    "app",
+		"replica": 1,
+		"db":      map[string]interface{}{"host": "localhost", "port": 1},
+		"tags":    []interface{}{},
+		"pools":   map[string]interface{}{"a": 1},
+		"pool":    "b",
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.pool: "b" is not a key of pools, expected one of \["a"\]`)
+
+	out, err := schema.CoerceAll(sch, map[string]interface{}{
+		"name":    "app",
+		"replica": 1,
+		"db":      map[string]interface{}{"host": "localhost", "port": 1},
+		"tags":    []interface{}{},
+		"pools":   map[string]interface{}{"a": 1},
+		"pool":    "a",
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out.(map[string]interface{})["replica"], gc.Equals, int64(1))
+
+	// FieldMaps within lists report all their errors, but lists stop
+	// at their first element in error.
+	_, err = schema.CoerceAll(schema.List(db), []interface{}{
+		map[string]interface{}{"host": 1, "port": "x"},
+		map[string]interface{}{"host": 2},
+	}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\[0\]\.host: expected string, got int\(1\); <path>\[0\]\.port: expected int, got string\("x"\)`)
+
+	// Checkers other than FieldMaps report a single error.
+	_, err = schema.CoerceAll(schema.Int(), "x", aPath)
+	c.Check(err.(*schema.ErrorList).Errors(), gc.HasLen, 1)
+	c.Check(err, gc.ErrorMatches, `<path>: expected int, got string\("x"\)`)
+
+	// Coerce still stops at the first error.
+	_, err = sch.Coerce(map[string]interface{}{"name": 1, "replica": "x"}, aPath)
+	c.Check(err, gc.Not(gc.FitsTypeOf), &schema.ErrorList{})
+}
+
+func (s *S) TestFlagPresent(c *gc.C) {
+	sch := schema.StrictFieldMap(schema.Fields{
+		"name":  schema.String(),
+		"debug": schema.FlagPresent(),
+	}, schema.Defaults{
+		"debug": true,
+	})
+
+	out, err := sch.Coerce(map[string]interface{}{"name": "a", "debug": nil}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"name": "a", "debug": true})
+
+	out, err = sch.Coerce(map[string]interface{}{"name": "a", "debug": "no"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"name": "a", "debug": true})
+
+	// The default is ignored.
+	out, err = sch.Coerce(map[string]interface{}{"name": "a"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"name": "a", "debug": false})
+
+	out, err = schema.FlagPresent().Coerce("x", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, true)
+
+	out, err = schema.FlagPresent().Coerce(nil, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, false)
+}
+
+func (s *S) TestCoerceWithWarnings(c *gc.C) {
+	sch := schema.Deprecated(schema.FieldMap(schema.Fields{
+		"api-port": schema.Int(),
+		"port":     schema.Int(),
+		"debug":    schema.FlagPresent(),
+		"proxy": schema.Deprecated(schema.FieldMap(schema.Fields{
+			"url":  schema.String(),
+			"host": schema.String(),
+		}, schema.Defaults{"url": schema.Omit, "host": schema.Omit}), map[string]string{
+			"host": `use "url" instead`,
+		}),
+	}, schema.Defaults{
+		"api-port": schema.Omit,
+		"port":     schema.Omit,
+		"proxy":    schema.Omit,
+	}), map[string]string{
+		"port":  `use "api-port" instead`,
+		"debug": "",
+	})
+
+	out, warnings, err := schema.CoerceWithWarnings(sch, map[string]interface{}{
+		"port":  "17070",
+		"debug": nil,
+		"proxy": map[string]interface{}{"host": "squid"},
+	}, nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{
+		"port":  int64(17070),
+		"debug": true,
+		"proxy": map[string]interface{}{"host": "squid"},
+	})
+	c.Assert(warnings, gc.DeepEquals, []string{
+		"debug is deprecated",
+		`port is deprecated: use "api-port" instead`,
+		`proxy.host is deprecated: use "url" instead`,
+	})
+
+	out, warnings, err = schema.CoerceWithWarnings(sch, map[string]interface{}{"api-port": 17070}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"api-port": int64(17070), "debug": false})
+	c.Assert(warnings, gc.HasLen, 0)
+
+	_, warnings, err = schema.CoerceWithWarnings(sch, map[string]interface{}{"port": "x"}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\.port: expected int, got string\("x"\)`)
+	c.Assert(warnings, gc.DeepEquals, []string{`<path>.port is deprecated: use "api-port" instead`})
+
+	// Coerce ignores warnings.
+	out, err = sch.Coerce(map[string]interface{}{"port": 1}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"port": int64(1), "debug": false})
+
+	// Warnings are gathered from FieldMaps within lists and other
+	// checkers, but only from the OneOf option that succeeds.
+	server := schema.Deprecated(schema.FieldMap(schema.Fields{
+		"addr": schema.String(),
+		"host": schema.String(),
+	}, schema.Defaults{"addr": schema.Omit, "host": schema.Omit}), map[string]string{
+		"host": `use "addr" instead`,
+	})
+	list := schema.FieldMap(schema.Fields{
+		"servers": schema.List(schema.Nullable(server)),
+		"backup": schema.OneOf(
+			schema.Deprecated(schema.StrictFieldMap(schema.Fields{"host": schema.Int()}, nil), map[string]string{
+				"host": "numeric hosts are deprecated",
+			}),
+			server,
+		),
+	}, schema.Defaults{"backup": schema.Omit})
+	_, warnings, err = schema.CoerceWithWarnings(list, map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"addr": "a"},
+			map[string]interface{}{"host": "b"},
+			nil,
+		},
+		"backup": map[string]interface{}{"host": "c"},
+	}, nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(warnings, gc.DeepEquals, []string{
+		`backup.host is deprecated: use "addr" instead`,
+		`servers[1].host is deprecated: use "addr" instead`,
+	})
+
+	c.Assert(func() { schema.Deprecated(schema.Int(), nil) }, gc.PanicMatches, "Deprecated got a non-FieldMap checker")
+	c.Assert(func() {
+		schema.Deprecated(schema.FieldMap(schema.Fields{}, nil), map[string]string{"a": ""})
+	}, gc.PanicMatches, `Deprecated got a message for unknown field "a"`)
+}
+
+func (s *S) TestCoerceWithStats(c *gc.C) {
+	sch := schema.FieldMap(schema.Fields{
+		"name": schema.String(),
+		"size": schema.Int(),
+		"db": schema.FieldMap(schema.Fields{
+			"host": schema.String(),
+			"port": schema.Int(),
+		}, schema.Defaults{
+			"port": 5432,
+		}),
+		"tags": schema.List(schema.String()),
+	}, schema.Defaults{
+		"size": 1,
+		"tags": schema.Omit,
+	})
+
+	out, stats, err := schema.CoerceWithStats(sch, map[string]interface{}{
+		"name":  "app",
+		"db":    map[string]interface{}{"host": "localhost", "user": "root"},
+		"extra": true,
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{
+		"name": "app",
+		"size": int64(1),
+		"db":   map[string]interface{}{"host": "localhost", "port": int64(5432)},
+	})
+	c.Assert(stats, gc.Equals, schema.CoerceStats{
+		Fields:   5,
+		Defaults: 2,
+		Unknown:  2,
+	})
+
+	_, stats, err = schema.CoerceWithStats(schema.Int(), "1", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.Equals, schema.CoerceStats{})
+
+	// FieldMaps within lists are counted too.
+	_, stats, err = schema.CoerceWithStats(schema.List(sch), []interface{}{
+		map[string]interface{}{"name": "a", "db": map[string]interface{}{"host": "h"}},
+		map[string]interface{}{"name": "b", "size": 2, "db": map[string]interface{}{"host": "h", "port": 1}},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.Equals, schema.CoerceStats{
+		Fields:   10,
+		Defaults: 2,
+	})
+
+	// Dependency constraints are counted each time they are checked,
+	// unlike other constraints.
+	deps := schema.Constrained(sch,
+		schema.Dependency{Field: "tags", On: "name", Values: []interface{}{"b"}},
+		schema.Dependency{Field: "size", On: "name", OnPresence: true},
+		schema.RefersToKeysOf("name", "db"))
+	_, stats, err = schema.CoerceWithStats(schema.List(deps), []interface{}{
+		map[string]interface{}{"name": "host", "db": map[string]interface{}{"host": "h", "a": 1}},
+		map[string]interface{}{"name": "port", "db": map[string]interface{}{"host": "h"}},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.Equals, schema.CoerceStats{
+		Fields:       10,
+		Defaults:     4,
+		Dependencies: 4,
+		Unknown:      1,
+	})
+
+	_, _, err = schema.CoerceWithStats(sch, map[string]interface{}{
+		"name": 1,
+		"db":   map[string]interface{}{"host": "localhost"},
+	}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\.name: expected string, got int\(1\)`)
+}
+
+func (s *S) TestProtoOneOf(c *gc.C) {
+	sch := schema.ProtoOneOf(map[string]schema.Checker{
+		"file":   schema.String(),
+		"inline": schema.String(),
+		"size":   schema.Int(),
+	})
+
+	out, err := sch.Coerce(map[string]interface{}{"size": "10", "other": 1}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, schema.OneOfValue{Field: "size", Value: int64(10)})
+
+	out, err = sch.Coerce(map[interface{}]interface{}{"file": "/tmp/x"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, schema.OneOfValue{Field: "file", Value: "/tmp/x"})
+
+	_, err = sch.Coerce(map[string]interface{}{"other": 1}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected exactly one of \["file" "inline" "size"\], got \[\]`)
+
+	_, err = sch.Coerce(map[string]interface{}{"file": "a", "inline": "b"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected exactly one of \["file" "inline" "size"\], got \["file" "inline"\]`)
+
+	_, err = sch.Coerce(map[string]interface{}{"size": "big"}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>\.size: expected int, got string\("big"\)`)
+
+	_, err = sch.Coerce("file", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected map, got string\("file"\)`)
 }
 
 func (s *S) TestSchemaMap(c *gc.C) {
@@ -658,12 +2237,84 @@ func (s *S) TestSchemaMap(c *gc.C) {
 	c.Assert(out, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, `<path>: expected map, got nothing`)
 
+	out, err = sch.Coerce(map[int]int{1: 1}, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: expected map\[string\], got map\[int\]int\(map\[int\]int{1:1}\)`)
+
 	// First path entry shouldn't have dots in an error message.
 	out, err = sch.Coerce(map[string]int{"a": 1}, nil)
 	c.Assert(out, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, `type: expected supported selector, got nothing`)
 }
 
+func (s *S) TestOverlappingSchemaMap(c *gc.C) {
+	byName := schema.StrictFieldMap(schema.Fields{
+		"type": schema.Const("disk"),
+		"name": schema.String(),
+	}, nil)
+	byID := schema.StrictFieldMap(schema.Fields{
+		"type": schema.Const("disk"),
+		"id":   schema.Int(),
+	}, nil)
+	other := schema.FieldMap(schema.Fields{
+		"type": schema.Const("net"),
+	}, nil)
+	maps := []schema.Checker{byName, byID, other}
+
+	// FieldMapSet only ever tries the first map for a selector.
+	_, err := schema.FieldMapSet("type", maps).Coerce(map[string]interface{}{"type": "disk", "id": 1}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: unknown key "id" \(value 1\)`)
+
+	sch := schema.OverlappingFieldMapSet("type", maps)
+
+	out, err := sch.Coerce(map[string]interface{}{"type": "disk", "id": 1}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"type": "disk", "id": int64(1)})
+
+	out, err = sch.Coerce(map[string]interface{}{"type": "disk", "name": "a"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"type": "disk", "name": "a"})
+
+	out, err = sch.Coerce(map[string]interface{}{"type": "net", "id": 1}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{"type": "net"})
+
+	_, err = sch.Coerce(map[string]interface{}{"type": "disk", "size": 1}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: no map for selector "disk" matched: <path>: unknown key "size" \(value 1\); <path>: unknown key "size" \(value 1\)`)
+
+	_, err = sch.Coerce(map[string]interface{}{"type": "nfs"}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\.type: expected supported selector, got string\("nfs"\)`)
+
+	// A single failing candidate reports its error unchanged.
+	sch = schema.OverlappingFieldMapSet("type", []schema.Checker{byID, other})
+	_, err = sch.Coerce(map[string]interface{}{"type": "disk", "id": "x"}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\.id: expected int, got string\("x"\)`)
+}
+
+func (s *S) TestULID(c *gc.C) {
+	sch := schema.ULID()
+
+	out, err := sch.Coerce("01ARZ3NDEKTSV4RRFFQ69G5FAV", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+
+	out, err = sch.Coerce("01arz3ndektsv4rrffq69g5fav", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+
+	_, err = sch.Coerce("01ARZ3NDEK", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid ULID "01ARZ3NDEK": expected 26 characters, got 10`)
+
+	_, err = sch.Coerce("01ARZ3NDEKTSV4RRFFQ69G5FAU", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid ULID "01ARZ3NDEKTSV4RRFFQ69G5FAU": character 'U' is not in the Crockford base32 alphabet`)
+
+	_, err = sch.Coerce("81ARZ3NDEKTSV4RRFFQ69G5FAV", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid ULID "81ARZ3NDEKTSV4RRFFQ69G5FAV": first character must be between 0 and 7`)
+
+	_, err = sch.Coerce(42, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(42\)`)
+}
+
 func (s *S) TestUUID(c *gc.C) {
 	sch := schema.UUID()
 
@@ -671,13 +2322,34 @@ func (s *S) TestUUID(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(out, gc.Equals, "6216dfc3-6e82-408f-9f74-8565e63e6158")
 
-	out, err = sch.Coerce("uuid", aPath)
-	c.Assert(out, gc.IsNil)
-	c.Assert(err, gc.ErrorMatches, `<path>: expected uuid, got string\(\"uuid\"\)`)
+	out, err = sch.Coerce("6216DFC3-6E82-408F-9F74-8565E63E6158", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "6216dfc3-6e82-408f-9f74-8565e63e6158")
+
+	for _, in := range []string{
+		"not-a-uuid",
+		"x6216dfc3-6e82-408f-9f74-8565e63e6158",
+		"6216dfc36e82408f9f748565e63e6158",
+		"{6216dfc3-6e82-408f-9f74-8565e63e6158}",
+	} {
+		out, err = sch.Coerce(in, aPath)
+		c.Assert(out, gc.IsNil)
+		c.Assert(err.Error(), gc.Equals, fmt.Sprintf(`<path>: %q is not a valid UUID`, in))
+	}
 
 	out, err = sch.Coerce(nil, aPath)
 	c.Assert(out, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, "<path>: expected uuid, got nothing")
+
+	sch = schema.UUID(schema.AllowBraces)
+	for _, in := range []string{"{6216DFC3-6E82-408F-9F74-8565E63E6158}", "6216dfc3-6e82-408f-9f74-8565e63e6158"} {
+		out, err = sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, "6216dfc3-6e82-408f-9f74-8565e63e6158")
+	}
+	out, err = sch.Coerce("{6216dfc3-6e82-408f-9f74-8565e63e6158", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: "{6216dfc3-6e82-408f-9f74-8565e63e6158" is not a valid UUID`)
 }
 
 func (s *S) TestTime(c *gc.C) {
@@ -707,6 +2379,94 @@ func (s *S) TestTime(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, "<path>: expected string or time.Time, got nothing")
 }
 
+func (s *S) TestTimeFormat(c *gc.C) {
+	sch := schema.TimeFormat("2006-01-02 15:04")
+
+	out, err := sch.Coerce("2016-10-09 12:34", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, time.Date(2016, 10, 9, 12, 34, 0, 0, time.UTC))
+
+	out, err = sch.Coerce("2016-10-09T12:34:00Z", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: conversion to time: parsing time "2016-10-09T12:34:00Z" as "2006-01-02 15:04": cannot parse "T12:34:00Z" as " "`)
+
+	out, err = sch.Coerce(int64(1476016496), aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected string or time.Time, got int64(1476016496)")
+}
+
+func (s *S) TestTimeAllowUnix(c *gc.C) {
+	sch := schema.Time(schema.AllowUnix)
+	value := time.Date(2016, 10, 9, 12, 34, 56, 0, time.UTC)
+
+	for _, in := range []interface{}{1476016496, int64(1476016496), uint32(1476016496), value.Format(time.RFC3339)} {
+		out, err := sch.Coerce(in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Assert(out, gc.Equals, value)
+	}
+
+	out, err := sch.Coerce(1.5, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected string, time.Time or Unix timestamp, got float64(1.5)")
+
+	sch = schema.TimeFormat("02/01/2006", schema.AllowUnix)
+	out, err = sch.Coerce("09/10/2016", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, time.Date(2016, 10, 9, 0, 0, 0, 0, time.UTC))
+	out, err = sch.Coerce(0, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, time.Unix(0, 0).UTC())
+}
+
+func (s *S) TestTimeOfDay(c *gc.C) {
+	sch := schema.TimeOfDay()
+
+	out, err := sch.Coerce("09:30", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 9*time.Hour+30*time.Minute)
+
+	out, err = sch.Coerce("23:59:59", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, 24*time.Hour-time.Second)
+
+	out, err = sch.Coerce("25:00", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: conversion to time of day: parsing time "25:00": hour out of range`)
+
+	out, err = sch.Coerce("noon", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `<path>: conversion to time of day: parsing time "noon" .*`)
+
+	out, err = sch.Coerce(42, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected string, got int(42)")
+}
+
+func (s *S) TestTimeOfDayRange(c *gc.C) {
+	sch := schema.TimeOfDayRange("09:00", "17:00")
+
+	for _, t := range []string{"09:00", "12:00", "17:00"} {
+		_, err := sch.Coerce(t, aPath)
+		c.Check(err, gc.IsNil)
+	}
+	out, err := sch.Coerce("17:00:01", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected time of day between 09:00 and 17:00, got string("17:00:01")`)
+
+	// Ranges wrap around midnight.
+	sch = schema.TimeOfDayRange("22:00", "06:00")
+	for _, t := range []string{"22:00", "23:30", "00:00", "06:00"} {
+		_, err := sch.Coerce(t, aPath)
+		c.Check(err, gc.IsNil)
+	}
+	out, err = sch.Coerce("12:00", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, `<path>: expected time of day between 22:00 and 06:00, got string("12:00")`)
+
+	c.Assert(func() { schema.TimeOfDayRange("9am", "17:00") }, gc.PanicMatches, "TimeOfDayRange got an invalid start time: .*")
+	c.Assert(func() { schema.TimeOfDayRange("09:00", "24:00") }, gc.PanicMatches, "TimeOfDayRange got an invalid end time: .*")
+}
+
 func (s *S) TestStringified(c *gc.C) {
 	sch := schema.Stringified()
 
@@ -737,6 +2497,46 @@ func (s *S) TestStringified(c *gc.C) {
 	out, err = sch.Coerce(map[string]string{"a": "b"}, aPath)
 	c.Assert(err, gc.IsNil)
 	c.Check(out, gc.Equals, `map[string]string{"a":"b"}`)
+
+}
+
+func (s *S) TestStringifiedThen(c *gc.C) {
+	sch := schema.StringifiedThen(schema.NonEmptyString("port"))
+
+	tests := []struct {
+		in  interface{}
+		out string
+	}{
+		{true, "true"},
+		{8080, "8080"},
+		{uint8(7), "7"},
+		{2.50, "2.5"},
+		{float64(3), "3"},
+		{"http", "http"},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %#v", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	// The string is validated by the inner checker.
+	sch = schema.StringifiedThen(schema.Int())
+	out, err := sch.Coerce(8080, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, int64(8080))
+	_, err = sch.Coerce("spam", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected int, got string\("spam"\)`)
+	_, err = sch.Coerce(true, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected int, got string\("true"\)`)
+
+	_, err = sch.Coerce(map[string]interface{}{"a": 1}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected bool, number or string, got map\[string\]interface \{\}\(.*\)`)
+	_, err = sch.Coerce([]interface{}{1}, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected bool, number or string, got \[\]interface \{\}\(.*\)`)
+	_, err = sch.Coerce(nil, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected bool, number or string, got nothing`)
 }
 
 func (s *S) TestSize(c *gc.C) {
@@ -767,3 +2567,197 @@ func (s *S) TestSize(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, "<path>: expected string, got int(0)")
 	c.Assert(out, gc.IsNil)
 }
+
+func (s *S) TestByteSize(c *gc.C) {
+	sch := schema.ByteSize()
+	tests := []struct {
+		in  interface{}
+		out uint64
+	}{
+		{"0", 0},
+		{"1023", 1023},
+		{"10B", 10},
+		{"4G", 4 << 30},
+		{"512M", 512 << 20},
+		{"1.5Gi", 3 << 29},
+		{"2KiB", 2048},
+		{"2kB", 2000},
+		{"2KB", 2000},
+		{"1.5GB", 1500000000},
+		{"1EiB", 1 << 60},
+		{int(10), 10},
+		{int64(4096), 4096},
+		{uint64(1 << 40), 1 << 40},
+		{float64(1.5), 2},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %#v", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	for _, in := range []string{"4Q", "", "G", "-1", "1.2.3G", "16EiB"} {
+		_, err := sch.Coerce(in, aPath)
+		c.Check(err, gc.ErrorMatches, fmt.Sprintf(`<path>: invalid size %q`, in))
+	}
+	_, err := sch.Coerce(-1, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: invalid size -1`)
+	_, err = sch.Coerce(nil, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected size, got nothing`)
+	_, err = sch.Coerce(true, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected size, got bool\(true\)`)
+}
+
+func (s *S) TestByteSizeString(c *gc.C) {
+	sch := schema.ByteSizeString()
+	tests := []struct {
+		in  interface{}
+		out string
+	}{
+		{"0B", "0"},
+		{"1023", "1023"},
+		{"4G", "4GiB"},
+		{"1536M", "1536MiB"},
+		{"1.5GB", "1500MB"},
+		{"2kB", "2kB"},
+		{1024, "1KiB"},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %#v", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+
+		// The canonical form parses back to the same size.
+		want, err := schema.ByteSize().Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		got, err := schema.ByteSize().Coerce(out, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(got, gc.Equals, want)
+	}
+}
+
+func (s *S) TestSizeAtLeast(c *gc.C) {
+	sch := schema.SizeAtLeast("256MiB")
+
+	out, err := sch.Coerce("256M", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, uint64(256))
+
+	out, err = sch.Coerce("1G", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, uint64(1024))
+
+	out, err = sch.Coerce("128", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected at least 256MiB, got 128MiB")
+
+	sch = schema.SizeAtLeast("2G")
+	out, err = sch.Coerce("1.5G", aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected at least 2GiB, got 1536MiB")
+
+	out, err = sch.Coerce(nil, aPath)
+	c.Assert(out, gc.IsNil)
+	c.Assert(err.Error(), gc.Equals, "<path>: expected string, got nothing")
+
+	c.Assert(func() { schema.SizeAtLeast("lots") }, gc.PanicMatches, `SizeAtLeast got an invalid minimum size: .*`)
+}
+
+func (s *S) TestCoerceError(c *gc.C) {
+	sch := schema.FieldMap(schema.Fields{
+		"servers": schema.List(schema.FieldMap(schema.Fields{
+			"port": schema.ForceInt(),
+		}, nil)),
+	}, nil)
+
+	_, err := sch.Coerce(map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"port": 80},
+			map[string]interface{}{"port": true},
+		},
+	}, nil)
+	c.Assert(err, gc.ErrorMatches, `servers\[1\]\.port: expected number, got bool\(true\)`)
+	cerr, ok := err.(schema.CoerceError)
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(cerr.Path, gc.DeepEquals, []string{".", "servers", "[", "1", "]", ".", "port"})
+	c.Assert(cerr.Expected, gc.Equals, "number")
+	c.Assert(cerr.Got, gc.Equals, true)
+	c.Assert(cerr.Message, gc.Equals, "")
+
+	_, err = schema.Port().Coerce(70000, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: port 70000 out of range \[1, 65535\]`)
+	cerr, ok = err.(schema.CoerceError)
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(cerr.Path, gc.DeepEquals, aPath)
+	c.Assert(cerr.Message, gc.Equals, "port 70000 out of range [1, 65535]")
+}
+
+func (s *S) TestFieldPath(c *gc.C) {
+	sch := schema.FieldMap(schema.Fields{"name": schema.String()}, nil)
+	_, err := sch.Coerce(map[string]interface{}{"name": 1}, nil)
+	path, ok := schema.FieldPath(err)
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(path, gc.DeepEquals, []string{".", "name"})
+
+	path, ok = schema.FieldPath(fmt.Errorf("cannot read config: %w", err))
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(path, gc.DeepEquals, []string{".", "name"})
+
+	path, ok = schema.FieldPath(fmt.Errorf("other"))
+	c.Assert(ok, gc.Equals, false)
+	c.Assert(path, gc.IsNil)
+}
+
+func (s *S) TestLazy(c *gc.C) {
+	calls := 0
+	var node schema.Checker
+	node = schema.FieldMap(schema.Fields{
+		"name": schema.String(),
+		"children": schema.List(schema.Lazy(func() schema.Checker {
+			calls++
+			return node
+		})),
+	}, schema.Defaults{"children": schema.Omit})
+
+	in := map[string]interface{}{
+		"name": "root",
+		"children": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{
+				"name":     "b",
+				"children": []interface{}{map[string]interface{}{"name": "c"}},
+			},
+		},
+	}
+	out, err := node.Coerce(in, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{
+		"name": "root",
+		"children": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{
+				"name":     "b",
+				"children": []interface{}{map[string]interface{}{"name": "c"}},
+			},
+		},
+	})
+	c.Assert(calls, gc.Equals, 1)
+
+	in["children"].([]interface{})[1].(map[string]interface{})["children"] = []interface{}{map[string]interface{}{"name": 1}}
+	_, err = node.Coerce(in, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\.children\[1\]\.children\[0\]\.name: expected string, got int\(1\)`)
+}
+
+func (s *S) TestLazyCyclicInput(c *gc.C) {
+	var list schema.Checker
+	list = schema.List(schema.Lazy(func() schema.Checker { return list }))
+
+	in := []interface{}{nil}
+	in[0] = in
+	_, err := list.Coerce(in, nil)
+	c.Assert(err, gc.ErrorMatches, `(\[0\])+: value nested more than 1000 levels deep`)
+	path, _ := schema.FieldPath(err)
+	c.Assert(path, gc.HasLen, 3*1001)
+}
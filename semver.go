@@ -0,0 +1,139 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// SemVerOption holds an option for SemVer.
+type SemVerOption int
+
+const (
+	// AllowVPrefix makes SemVer accept versions prefixed with "v", as
+	// in "v1.2.3".
+	AllowVPrefix SemVerOption = iota + 1
+	// AllowShort makes SemVer accept versions omitting their minor or
+	// patch numbers, as in "1.2", which are taken to be zero.
+	AllowShort
+)
+
+// SemVer returns a Checker that accepts a string holding a semantic
+// version as specified at https://semver.org, such as "1.2.3-rc.1", and
+// returns it in normalized form, without any "v" prefix and with all
+// three version numbers, so that with the AllowVPrefix and AllowShort
+// options "v1.2" becomes "1.2.0".
+func SemVer(options ...SemVerOption) Checker {
+	var c semVerC
+	for _, opt := range options {
+		switch opt {
+		case AllowVPrefix:
+			c.allowVPrefix = true
+		case AllowShort:
+			c.allowShort = true
+		}
+	}
+	return c
+}
+
+type semVerC struct {
+	allowVPrefix bool
+	allowShort   bool
+}
+
+var semVerRegexp = regexp.MustCompile(`^(v?)(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*))?(?:\.(0|[1-9][0-9]*))?` +
+	`(-(?:0|[1-9][0-9]*|[0-9]*[A-Za-z-][0-9A-Za-z-]*)(?:\.(?:0|[1-9][0-9]*|[0-9]*[A-Za-z-][0-9A-Za-z-]*))*)?` +
+	`(\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?$`)
+
+func (c semVerC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	m := semVerRegexp.FindStringSubmatch(s)
+	switch {
+	case m == nil,
+		m[1] != "" && !c.allowVPrefix,
+		m[4] == "" && !c.allowShort:
+		return nil, errorf(path, "%q is not a valid semantic version", s)
+	}
+	minor, patch := m[3], m[4]
+	if minor == "" {
+		minor = "0"
+	}
+	if patch == "" {
+		patch = "0"
+	}
+	return m[2] + "." + minor + "." + patch + m[5] + m[6], nil
+}
+
+// SemVerConstraint returns a Checker that accepts a string holding a
+// semantic version constraint, such as ">=1.2.0 <2.0.0", "^1.2" or
+// "~1.4 || >=2", and returns it in normalized form, with no space
+// between operators and versions, comparators separated by a single
+// space and alternatives by " || ". Operators may be one of "=", "!=",
+// ">", ">=", "<", "<=", "~" and "^", with "=" assumed when omitted, and
+// versions may omit their minor and patch numbers or use "x" or "*" as
+// a wildcard for them.
+//
+// For other constraint syntaxes, use Selector with the parse function
+// of the library implementing them.
+func SemVerConstraint() Checker {
+	return semVerConstraintC{}
+}
+
+type semVerConstraintC struct{}
+
+var (
+	semVerComparatorRegexp = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~|\^)?\s*(.*)$`)
+	semVerPartialRegexp    = regexp.MustCompile(`^v?(0|[1-9][0-9]*|[xX*])(?:\.(0|[1-9][0-9]*|[xX*])(?:\.(0|[1-9][0-9]*|[xX*])(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)?)?$`)
+)
+
+func (c semVerConstraintC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	constraint, err := normalizeSemVerConstraint(s)
+	if err != nil {
+		return nil, errorf(path, "invalid version constraint %q: %v", s, err)
+	}
+	return constraint, nil
+}
+
+func normalizeSemVerConstraint(s string) (string, error) {
+	var alternatives []string
+	for _, alt := range strings.Split(s, "||") {
+		// Operators may be separated from their versions by spaces,
+		// so glue them back together before splitting comparators.
+		var fields []string
+		pending := ""
+		for _, f := range strings.FieldsFunc(alt, func(r rune) bool { return r == ' ' || r == ',' }) {
+			if semVerComparatorRegexp.FindStringSubmatch(f)[2] == "" {
+				pending += f
+				continue
+			}
+			fields = append(fields, pending+f)
+			pending = ""
+		}
+		if pending != "" {
+			return "", fmt.Errorf("operator %q without version", pending)
+		}
+		if len(fields) == 0 {
+			return "", fmt.Errorf("empty constraint")
+		}
+		for i, f := range fields {
+			m := semVerComparatorRegexp.FindStringSubmatch(f)
+			if !semVerPartialRegexp.MatchString(m[2]) {
+				return "", fmt.Errorf("invalid version %q", m[2])
+			}
+			fields[i] = m[1] + m[2]
+		}
+		alternatives = append(alternatives, strings.Join(fields, " "))
+	}
+	return strings.Join(alternatives, " || "), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"fmt"
+	"regexp"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type semverSuite struct{}
+
+var _ = gc.Suite(&semverSuite{})
+
+func (s *semverSuite) TestSemVerConstraint(c *gc.C) {
+	sch := schema.SemVerConstraint()
+
+	tests := []struct {
+		in, out string
+	}{
+		{"1.2.3", "1.2.3"},
+		{">=1.2.0 <2.0.0", ">=1.2.0 <2.0.0"},
+		{">= 1.2.0,  < 2.0.0", ">=1.2.0 <2.0.0"},
+		{"^1.2", "^1.2"},
+		{"~1.4||>=2", "~1.4 || >=2"},
+		{"1.x", "1.x"},
+		{"!=1.2.3-beta.1+build.5", "!=1.2.3-beta.1+build.5"},
+		{"= v1", "=v1"},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.in)
+		out, err := sch.Coerce(test.in, aPath)
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	errTests := []struct {
+		in  interface{}
+		err string
+	}{
+		{"", `<path>: invalid version constraint "": empty constraint`},
+		{">=1.0 ||", `<path>: invalid version constraint ">=1.0 \|\|": empty constraint`},
+		{">=1.2.3.4", `<path>: invalid version constraint ">=1.2.3.4": invalid version "1.2.3.4"`},
+		{"=>1.0", `<path>: invalid version constraint "=>1.0": invalid version ">1.0"`},
+		{"1.0 <", `<path>: invalid version constraint "1.0 <": operator "<" without version`},
+		{"01.2", `<path>: invalid version constraint "01.2": invalid version "01.2"`},
+		{1, `<path>: expected string, got int\(1\)`},
+	}
+	for i, test := range errTests {
+		c.Logf("test %d: %v", i, test.in)
+		_, err := sch.Coerce(test.in, aPath)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *semverSuite) TestSemVer(c *gc.C) {
+	tests := []struct {
+		options []schema.SemVerOption
+		in      string
+		out     string
+		err     bool
+	}{
+		{in: "1.2.3", out: "1.2.3"},
+		{in: "0.0.0", out: "0.0.0"},
+		{in: "1.2.3-rc.1+build.5", out: "1.2.3-rc.1+build.5"},
+		{in: "1.0.0-alpha-1", out: "1.0.0-alpha-1"},
+		{in: "1.x", err: true},
+		{in: "1.2", err: true},
+		{in: "v1.2.3", err: true},
+		{in: "01.2.3", err: true},
+		{in: "1.2.3-01", err: true},
+		{in: "1.2.3-", err: true},
+		{in: "1.2.3+", err: true},
+		{in: "", err: true},
+		{options: []schema.SemVerOption{schema.AllowVPrefix}, in: "v1.2.3", out: "1.2.3"},
+		{options: []schema.SemVerOption{schema.AllowVPrefix}, in: "v1.2", err: true},
+		{options: []schema.SemVerOption{schema.AllowShort}, in: "1.2", out: "1.2.0"},
+		{options: []schema.SemVerOption{schema.AllowShort}, in: "1", out: "1.0.0"},
+		{options: []schema.SemVerOption{schema.AllowShort}, in: "1.2-beta", out: "1.2.0-beta"},
+		{options: []schema.SemVerOption{schema.AllowVPrefix, schema.AllowShort}, in: "v2", out: "2.0.0"},
+	}
+	for i, test := range tests {
+		c.Logf("test %d: %q %v", i, test.in, test.options)
+		out, err := schema.SemVer(test.options...).Coerce(test.in, aPath)
+		if test.err {
+			c.Check(err, gc.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(`<path>: %q is not a valid semantic version`, test.in)))
+			continue
+		}
+		c.Assert(err, gc.IsNil)
+		c.Check(out, gc.Equals, test.out)
+	}
+
+	_, err := schema.SemVer().Coerce(1, aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: expected string, got int\(1\)`)
+}
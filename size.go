@@ -23,17 +23,17 @@ type sizeC struct{}
 // Coerce implements Checker Coerce method.
 func (c sizeC) Coerce(v interface{}, path []string) (interface{}, error) {
 	if v == nil {
-		return nil, error_{"string", v, path}
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
 	}
 
 	typeOf := reflect.TypeOf(v).Kind()
 	if typeOf != reflect.String {
-		return nil, error_{"string", v, path}
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
 	}
 
 	value := reflect.ValueOf(v).String()
 	if value == "" {
-		return nil, error_{"empty string", v, path}
+		return nil, CoerceError{Expected: "empty string", Got: v, Path: path}
 	}
 
 	v, err := parseSize(value)
@@ -90,3 +90,174 @@ var sizeSuffixes = "MGTPEZY"
 func sizeSuffixMultiplier(i int) int {
 	return 1 << uint(i*10)
 }
+
+// SizeAtLeast returns a Checker that acts as the one returned by Size,
+// but additionally requires the parsed size to be at least min, which
+// is itself parsed as a size. It panics if min is not a valid size.
+func SizeAtLeast(min string) Checker {
+	minMB, err := parseSize(min)
+	if err != nil {
+		panic(fmt.Sprintf("SizeAtLeast got an invalid minimum size: %v", err))
+	}
+	return sizeAtLeastC{minMB}
+}
+
+type sizeAtLeastC struct {
+	min uint64
+}
+
+// Coerce implements Checker Coerce method.
+func (c sizeAtLeastC) Coerce(v interface{}, path []string) (interface{}, error) {
+	v, err := sizeC{}.Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	if size := v.(uint64); size < c.min {
+		return nil, errorf(path, "expected at least %s, got %s", formatSize(c.min), formatSize(size))
+	}
+	return v, nil
+}
+
+// formatSize renders a size in mebibytes using the largest binary
+// suffix that represents it exactly.
+func formatSize(MB uint64) string {
+	i := 0
+	for MB != 0 && i+1 < len(sizeSuffixes) && MB%1024 == 0 {
+		MB /= 1024
+		i++
+	}
+	return fmt.Sprintf("%d%ciB", MB, sizeSuffixes[i])
+}
+
+// ByteSize returns a Checker that accepts a size as a string, such as
+// "4G", "512MB" or "1.5GiB", and returns it as a uint64 number of
+// bytes. Unlike Size, a bare number means bytes. SI suffixes (kB, MB,
+// GB, ...) are powers of 1000, while binary suffixes (KiB, MiB, GiB,
+// ...) are powers of 1024; the short forms (K, M, G or Ki, Mi, Gi,
+// ...) are binary, as is usual in Juju. Numeric values are accepted as
+// a number of bytes.
+func ByteSize() Checker {
+	return byteSizeC{}
+}
+
+// ByteSizeString returns a Checker that accepts the same values as
+// ByteSize, and returns the size in its canonical string form, using
+// the largest binary suffix that represents it exactly, or otherwise
+// the largest such SI suffix, as in "4GiB", "1500MB" or "1023".
+func ByteSizeString() Checker {
+	return byteSizeC{str: true}
+}
+
+type byteSizeC struct {
+	str bool
+}
+
+// Coerce implements Checker Coerce method.
+func (c byteSizeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil {
+		return nil, CoerceError{Expected: "size", Got: v, Path: path}
+	}
+	var size uint64
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		var ok bool
+		size, ok = parseByteSize(rv.String())
+		if !ok {
+			return nil, errorf(path, "invalid size %q", rv.String())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < 0 {
+			return nil, errorf(path, "invalid size %d", rv.Int())
+		}
+		size = uint64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f < 0 || f >= math.MaxUint64 || math.IsNaN(f) {
+			return nil, errorf(path, "invalid size %v", f)
+		}
+		size = uint64(math.Ceil(f))
+	default:
+		return nil, CoerceError{Expected: "size", Got: v, Path: path}
+	}
+	if c.str {
+		return formatByteSize(size), nil
+	}
+	return size, nil
+}
+
+// byteSizeUnits holds the multipliers of the suffixes accepted by
+// ByteSize, from the largest down.
+var byteSizeUnits = []struct {
+	prefix string
+	si     uint64
+	binary uint64
+}{
+	{"E", 1e18, 1 << 60},
+	{"P", 1e15, 1 << 50},
+	{"T", 1e12, 1 << 40},
+	{"G", 1e9, 1 << 30},
+	{"M", 1e6, 1 << 20},
+	{"K", 1e3, 1 << 10},
+}
+
+// parseByteSize parses str as a size in bytes, reporting whether it
+// was valid.
+func parseByteSize(str string) (uint64, bool) {
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return r != '.' && !unicode.IsDigit(r)
+	})
+	var multiplier uint64 = 1
+	if i >= 0 {
+		suffix := str[i:]
+		str = str[:i]
+		multiplier = 0
+		if suffix == "B" {
+			multiplier = 1
+		}
+		for _, u := range byteSizeUnits {
+			switch suffix {
+			case u.prefix, u.prefix + "i", u.prefix + "iB":
+				multiplier = u.binary
+			case u.prefix + "B", strings.ToLower(u.prefix) + "B":
+				multiplier = u.si
+			}
+		}
+		if multiplier == 0 {
+			return 0, false
+		}
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil || val < 0 {
+		return 0, false
+	}
+	val = math.Ceil(val * float64(multiplier))
+	if val >= math.MaxUint64 {
+		return 0, false
+	}
+	return uint64(val), true
+}
+
+// formatByteSize renders a size in bytes in the canonical form
+// returned by ByteSizeString.
+func formatByteSize(size uint64) string {
+	if size == 0 {
+		return "0"
+	}
+	for _, u := range byteSizeUnits {
+		if size%u.binary == 0 {
+			return fmt.Sprintf("%d%siB", size/u.binary, u.prefix)
+		}
+	}
+	for _, u := range byteSizeUnits {
+		if size%u.si == 0 {
+			if u.prefix == "K" {
+				return fmt.Sprintf("%dkB", size/u.si)
+			}
+			return fmt.Sprintf("%d%sB", size/u.si, u.prefix)
+		}
+	}
+	return strconv.FormatUint(size, 10)
+}
@@ -0,0 +1,363 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// FromSpec returns the Checker described by spec, allowing schemas to be
+// defined as data rather than in Go code. A spec is a map holding the
+// checker type under "type", and any parameters for that type:
+//
+//	{"type": "any"}
+//	{"type": "bool"}
+//	{"type": "int", "min": 1, "max": 10}
+//	{"type": "uint", "min": 1, "max": 10}
+//	{"type": "float", "min": 0, "max": 1}
+//	{"type": "forceInt"}
+//	{"type": "forceUint"}
+//	{"type": "string"}
+//	{"type": "nonEmptyString", "label": "name"}
+//	{"type": "nil", "label": "value"}
+//	{"type": "const", "value": "foo"}
+//	{"type": "url", "schemes": ["http", "https"]}
+//	{"type": "uuid"}
+//	{"type": "regexp"}
+//	{"type": "time"}
+//	{"type": "duration"}
+//	{"type": "size"}
+//	{"type": "list", "elem": <spec>}
+//	{"type": "map", "key": <spec>, "value": <spec>}
+//	{"type": "stringMap", "value": <spec>}
+//	{"type": "oneOf", "options": [<spec>, ...]}
+//	{"type": "fieldMap", "fields": {"name": <spec>, ...},
+//	    "defaults": {"name": value, ...}, "optional": ["name", ...],
+//	    "strict": true}
+//
+// The "min" and "max" parameters are optional and inclusive, and the
+// optional "schemes" parameter restricts URLs as done by URL. Fields of
+// a fieldMap listed under "optional" are omitted from the coerced map
+// when missing, as with a schema.Omit default. Unknown types, unknown
+// parameters and missing required parameters all result in an error.
+func FromSpec(spec map[string]interface{}) (Checker, error) {
+	return fromSpec(spec, nil)
+}
+
+// FileSchema returns the Checker described by the spec held in the file
+// at path, as understood by FromSpec. This allows schemas to be shipped
+// as files alongside a binary, for instance by plugins. The file is read
+// and the Checker built when FileSchema is called, so any problem with
+// the file is reported then rather than when coercing values.
+//
+// The only format currently supported is "json".
+func FileSchema(path string, format string) (Checker, error) {
+	if format != "json" {
+		return nil, fmt.Errorf("unsupported schema format %q", format)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema: %v", err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("cannot parse schema %q: %v", path, err)
+	}
+	c, err := FromSpec(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %q: %v", path, err)
+	}
+	return c, nil
+}
+
+// specParams lists the parameters each spec type accepts, with those
+// that are required marked as true.
+var specParams = map[string]map[string]bool{
+	"any":            {},
+	"bool":           {},
+	"int":            {"min": false, "max": false},
+	"uint":           {"min": false, "max": false},
+	"float":          {"min": false, "max": false},
+	"forceInt":       {},
+	"forceUint":      {},
+	"string":         {},
+	"nonEmptyString": {"label": false},
+	"nil":            {"label": false},
+	"const":          {"value": true},
+	"url":            {"schemes": false},
+	"uuid":           {},
+	"regexp":         {},
+	"time":           {},
+	"duration":       {},
+	"size":           {},
+	"list":           {"elem": true},
+	"map":            {"key": true, "value": true},
+	"stringMap":      {"value": true},
+	"oneOf":          {"options": true},
+	"fieldMap":       {"fields": true, "defaults": false, "optional": false, "strict": false},
+}
+
+func fromSpec(spec map[string]interface{}, path []string) (Checker, error) {
+	t, err := String().Coerce(spec["type"], append(path, ".", "type"))
+	if err != nil {
+		return nil, err
+	}
+	typ := t.(string)
+	params, ok := specParams[typ]
+	if !ok {
+		return nil, errorf(path, "unknown type %q", typ)
+	}
+	for _, k := range sortedKeys(spec) {
+		if _, ok := params[k]; !ok && k != "type" {
+			return nil, errorf(path, "unknown key %q in %q spec", k, typ)
+		}
+	}
+	for _, k := range []string{"value", "elem", "key", "options", "fields"} {
+		if _, ok := spec[k]; params[k] && !ok {
+			return nil, errorf(path, "missing key %q in %q spec", k, typ)
+		}
+	}
+	label := func() (string, error) {
+		if spec["label"] == nil {
+			return "", nil
+		}
+		l, err := String().Coerce(spec["label"], append(path, ".", "label"))
+		if err != nil {
+			return "", err
+		}
+		return l.(string), nil
+	}
+	sub := func(key string) (Checker, error) {
+		m, ok := asStringMap(spec[key])
+		if !ok {
+			return nil, CoerceError{Expected: "spec map", Got: spec[key], Path: append(path, ".", key)}
+		}
+		return fromSpec(m, append(path, ".", key))
+	}
+
+	switch typ {
+	case "any":
+		return Any(), nil
+	case "bool":
+		return Bool(), nil
+	case "int":
+		return boundedSpec(Int(), spec, path)
+	case "uint":
+		return boundedSpec(Uint(), spec, path)
+	case "float":
+		return boundedSpec(Float(), spec, path)
+	case "forceInt":
+		return ForceInt(), nil
+	case "forceUint":
+		return ForceUint(), nil
+	case "string":
+		return String(), nil
+	case "nonEmptyString":
+		l, err := label()
+		if err != nil {
+			return nil, err
+		}
+		return NonEmptyString(l), nil
+	case "nil":
+		l, err := label()
+		if err != nil {
+			return nil, err
+		}
+		return Nil(l), nil
+	case "const":
+		return Const(spec["value"]), nil
+	case "url":
+		if spec["schemes"] == nil {
+			return URL(), nil
+		}
+		schemes, err := List(String()).Coerce(spec["schemes"], append(path, ".", "schemes"))
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, scheme := range schemes.([]interface{}) {
+			names = append(names, scheme.(string))
+		}
+		return URL(names...), nil
+	case "uuid":
+		return UUID(), nil
+	case "regexp":
+		return SimpleRegexp(), nil
+	case "time":
+		return Time(), nil
+	case "duration":
+		return TimeDuration(), nil
+	case "size":
+		return Size(), nil
+	case "list":
+		elem, err := sub("elem")
+		if err != nil {
+			return nil, err
+		}
+		return List(elem), nil
+	case "map":
+		key, err := sub("key")
+		if err != nil {
+			return nil, err
+		}
+		value, err := sub("value")
+		if err != nil {
+			return nil, err
+		}
+		return Map(key, value), nil
+	case "stringMap":
+		value, err := sub("value")
+		if err != nil {
+			return nil, err
+		}
+		return StringMap(value), nil
+	case "oneOf":
+		return oneOfSpec(spec, path)
+	case "fieldMap":
+		return fieldMapSpec(spec, path)
+	}
+	panic("unreachable")
+}
+
+func oneOfSpec(spec map[string]interface{}, path []string) (Checker, error) {
+	rv := reflect.ValueOf(spec["options"])
+	if rv.Kind() != reflect.Slice {
+		return nil, CoerceError{Expected: "list", Got: spec["options"], Path: append(path, ".", "options")}
+	}
+	options := make([]Checker, rv.Len())
+	for i := range options {
+		opath := append(path, ".", "options", "[", strconv.Itoa(i), "]")
+		m, ok := asStringMap(rv.Index(i).Interface())
+		if !ok {
+			return nil, CoerceError{Expected: "spec map", Got: rv.Index(i).Interface(), Path: opath}
+		}
+		option, err := fromSpec(m, opath)
+		if err != nil {
+			return nil, err
+		}
+		options[i] = option
+	}
+	return OneOf(options...), nil
+}
+
+func fieldMapSpec(spec map[string]interface{}, path []string) (Checker, error) {
+	fspecs, ok := asStringMap(spec["fields"])
+	if !ok {
+		return nil, CoerceError{Expected: "map[string]", Got: spec["fields"], Path: append(path, ".", "fields")}
+	}
+	// Build the fields in order, so that errors are deterministic.
+	fields := make(Fields, len(fspecs))
+	for _, name := range sortedKeys(fspecs) {
+		fpath := append(path, ".", "fields", ".", name)
+		m, ok := asStringMap(fspecs[name])
+		if !ok {
+			return nil, CoerceError{Expected: "spec map", Got: fspecs[name], Path: fpath}
+		}
+		field, err := fromSpec(m, fpath)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = field
+	}
+
+	defaults := make(Defaults)
+	if spec["defaults"] != nil {
+		m, ok := asStringMap(spec["defaults"])
+		if !ok {
+			return nil, CoerceError{Expected: "map[string]", Got: spec["defaults"], Path: append(path, ".", "defaults")}
+		}
+		for name, value := range m {
+			defaults[name] = value
+		}
+	}
+	if spec["optional"] != nil {
+		optional, err := List(String()).Coerce(spec["optional"], append(path, ".", "optional"))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range optional.([]interface{}) {
+			defaults[name.(string)] = Omit
+		}
+	}
+	for name := range defaults {
+		if _, ok := fields[name]; !ok {
+			return nil, errorf(path, "default for unknown field %q", name)
+		}
+	}
+
+	strict := false
+	if spec["strict"] != nil {
+		s, err := StrictBool().Coerce(spec["strict"], append(path, ".", "strict"))
+		if err != nil {
+			return nil, err
+		}
+		strict = s.(bool)
+	}
+	if strict {
+		return StrictFieldMap(fields, defaults), nil
+	}
+	return FieldMap(fields, defaults), nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// boundedSpec returns checker limited to the optional "min" and "max"
+// values found in spec.
+func boundedSpec(checker Checker, spec map[string]interface{}, path []string) (Checker, error) {
+	c := boundedC{checker: checker}
+	for _, k := range []string{"min", "max"} {
+		if spec[k] == nil {
+			continue
+		}
+		bound, err := Float().Coerce(spec[k], append(path, ".", k))
+		if err != nil {
+			return nil, err
+		}
+		b := bound.(float64)
+		if k == "min" {
+			c.min = &b
+		} else {
+			c.max = &b
+		}
+	}
+	if c.min == nil && c.max == nil {
+		return checker, nil
+	}
+	return c, nil
+}
+
+type boundedC struct {
+	checker  Checker
+	min, max *float64
+}
+
+func (c boundedC) Coerce(v interface{}, path []string) (interface{}, error) {
+	newv, err := c.checker.Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Float().Coerce(newv, path)
+	if err != nil {
+		return nil, err
+	}
+	if c.min != nil && f.(float64) < *c.min {
+		return nil, errorf(path, "%v is less than the minimum %v", newv, *c.min)
+	}
+	if c.max != nil && f.(float64) > *c.max {
+		return nil, errorf(path, "%v is greater than the maximum %v", newv, *c.max)
+	}
+	return newv, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schema_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/schema"
+)
+
+type specSuite struct{}
+
+var _ = gc.Suite(&specSuite{})
+
+func decodeSpec(c *gc.C, s string) map[string]interface{} {
+	var spec map[string]interface{}
+	err := json.Unmarshal([]byte(s), &spec)
+	c.Assert(err, gc.IsNil)
+	return spec
+}
+
+func (s *specSuite) TestFromSpec(c *gc.C) {
+	sch, err := schema.FromSpec(decodeSpec(c, `{
+		"type": "fieldMap",
+		"strict": true,
+		"fields": {
+			"name": {"type": "nonEmptyString", "label": "name"},
+			"replicas": {"type": "int", "min": 1, "max": 10},
+			"ratio": {"type": "float", "max": 1},
+			"mode": {"type": "oneOf", "options": [
+				{"type": "const", "value": "fast"},
+				{"type": "const", "value": "safe"}
+			]},
+			"tags": {"type": "list", "elem": {"type": "string"}},
+			"labels": {"type": "stringMap", "value": {"type": "string"}},
+			"timeout": {"type": "duration"}
+		},
+		"defaults": {"replicas": "1", "mode": "safe"},
+		"optional": ["ratio", "tags", "labels", "timeout"]
+	}`))
+	c.Assert(err, gc.IsNil)
+
+	out, err := sch.Coerce(map[string]interface{}{
+		"name": "app",
+		"tags": []interface{}{"a"},
+	}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[string]interface{}{
+		"name":     "app",
+		"replicas": int64(1),
+		"mode":     "safe",
+		"tags":     []interface{}{"a"},
+	})
+
+	_, err = sch.Coerce(map[string]interface{}{"name": "app", "replicas": 11}, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>.replicas: 11 is greater than the maximum 10")
+
+	_, err = sch.Coerce(map[string]interface{}{"name": "app", "replicas": 0}, aPath)
+	c.Assert(err.Error(), gc.Equals, "<path>.replicas: 0 is less than the minimum 1")
+
+	_, err = sch.Coerce(map[string]interface{}{"name": "app", "mode": "slow"}, aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>.mode: unexpected value "slow"`)
+
+	_, err = sch.Coerce(map[string]interface{}{"name": "app", "other": 1}, aPath)
+	c.Assert(err.Error(), gc.Equals, `<path>: unknown key "other" (value 1)`)
+}
+
+func (s *specSuite) TestFromSpecMap(c *gc.C) {
+	sch, err := schema.FromSpec(map[string]interface{}{
+		"type":  "map",
+		"key":   map[string]interface{}{"type": "string"},
+		"value": map[string]interface{}{"type": "uint"},
+	})
+	c.Assert(err, gc.IsNil)
+	out, err := sch.Coerce(map[string]int{"a": 1}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, map[interface{}]interface{}{"a": uint64(1)})
+}
+
+func (s *specSuite) TestFromSpecURLSchemes(c *gc.C) {
+	sch, err := schema.FromSpec(decodeSpec(c, `{"type": "url", "schemes": ["https"]}`))
+	c.Assert(err, gc.IsNil)
+	_, err = sch.Coerce("https://example.com", aPath)
+	c.Assert(err, gc.IsNil)
+	_, err = sch.Coerce("ftp://example.com", aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>: invalid URL "ftp://example.com": scheme "ftp" not allowed, expected one of \["https"\]`)
+
+	_, err = schema.FromSpec(decodeSpec(c, `{"type": "url", "schemes": "https"}`))
+	c.Assert(err, gc.ErrorMatches, `schemes: expected list, got string\("https"\)`)
+}
+
+func (s *specSuite) TestFromSpecErrors(c *gc.C) {
+	tests := []struct {
+		spec string
+		err  string
+	}{{
+		spec: `{}`,
+		err:  `type: expected string, got nothing`,
+	}, {
+		spec: `{"type": "integer"}`,
+		err:  `unknown type "integer"`,
+	}, {
+		spec: `{"type": "string", "min": 1}`,
+		err:  `unknown key "min" in "string" spec`,
+	}, {
+		spec: `{"type": "list"}`,
+		err:  `missing key "elem" in "list" spec`,
+	}, {
+		spec: `{"type": "list", "elem": "string"}`,
+		err:  `elem: expected spec map, got string\("string"\)`,
+	}, {
+		spec: `{"type": "int", "min": "one"}`,
+		err:  `min: expected float, got string\("one"\)`,
+	}, {
+		spec: `{"type": "oneOf", "options": [{"type": "int"}, {"type": "nope"}]}`,
+		err:  `options\[1\]: unknown type "nope"`,
+	}, {
+		spec: `{"type": "fieldMap", "fields": {"a": {"type": "list", "elem": {"type": "x"}}}}`,
+		err:  `fields\.a\.elem: unknown type "x"`,
+	}, {
+		spec: `{"type": "fieldMap", "fields": {"a": {"type": "int"}}, "optional": ["b"]}`,
+		err:  `default for unknown field "b"`,
+	}, {
+		spec: `{"type": "fieldMap", "fields": {}, "strict": "maybe"}`,
+		err:  `strict: expected bool, got string\("maybe"\)`,
+	}}
+	for i, test := range tests {
+		c.Logf("test %d: %s", i, test.spec)
+		sch, err := schema.FromSpec(decodeSpec(c, test.spec))
+		c.Check(sch, gc.IsNil)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *specSuite) TestFileSchema(c *gc.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "schema.json")
+	err := os.WriteFile(path, []byte(`{"type": "list", "elem": {"type": "int", "min": 1}}`), 0644)
+	c.Assert(err, gc.IsNil)
+
+	sch, err := schema.FileSchema(path, "json")
+	c.Assert(err, gc.IsNil)
+	out, err := sch.Coerce([]interface{}{1, "2"}, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []interface{}{int64(1), int64(2)})
+	_, err = sch.Coerce([]interface{}{0}, aPath)
+	c.Assert(err, gc.ErrorMatches, `<path>\[0\]: 0 is less than the minimum 1`)
+}
+
+func (s *specSuite) TestFileSchemaErrors(c *gc.C) {
+	dir := c.MkDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(content), 0644)
+		c.Assert(err, gc.IsNil)
+		return path
+	}
+
+	_, err := schema.FileSchema(write("a.yaml", "type: int"), "yaml")
+	c.Check(err, gc.ErrorMatches, `unsupported schema format "yaml"`)
+
+	_, err = schema.FileSchema(filepath.Join(dir, "missing.json"), "json")
+	c.Check(err, gc.ErrorMatches, `cannot read schema: .*`)
+
+	path := write("bad.json", `{"type":`)
+	_, err = schema.FileSchema(path, "json")
+	c.Check(err, gc.ErrorMatches, `cannot parse schema ".*bad.json": unexpected end of JSON input`)
+
+	path = write("unknown.json", `{"type": "nope"}`)
+	_, err = schema.FileSchema(path, "json")
+	c.Check(err, gc.ErrorMatches, `invalid schema ".*unknown.json": unknown type "nope"`)
+}
@@ -5,9 +5,14 @@ package schema
 
 import (
 	"fmt"
+	"io"
 	"net/url"
+	"path"
 	"reflect"
 	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
 )
 
 // String returns a Checker that accepts a string value only and returns
@@ -22,27 +27,80 @@ func (c stringC) Coerce(v interface{}, path []string) (interface{}, error) {
 	if v != nil && reflect.TypeOf(v).Kind() == reflect.String {
 		return reflect.ValueOf(v).String(), nil
 	}
-	return nil, error_{"string", v, path}
+	return nil, CoerceError{Expected: "string", Got: v, Path: path}
 }
 
 // URL returns a Checker that accepts a string value that must be parseable as a
 // URL, and returns a *net.URL.
-func URL() Checker {
-	return urlC{}
+//
+// Without schemes, any URL is accepted, including relative and host-less
+// ones such as "/path" and "foo", and it is returned as parsed. If any
+// schemes are provided, the URL must be absolute, with one of those
+// schemes, and must have a host, so that for instance URL("http",
+// "https") rejects "ftp://example.com" and "/path". The host of the URL
+// is then returned in lower case.
+func URL(schemes ...string) Checker {
+	return urlC{schemes}
+}
+
+// URLString returns a Checker that acts as the one returned by URL, but
+// returns the URL in its canonical string form.
+func URLString(schemes ...string) Checker {
+	return urlStringC{urlC{schemes}}
 }
 
-type urlC struct{}
+type urlC struct {
+	schemes []string
+}
 
 func (c urlC) Coerce(v interface{}, path []string) (interface{}, error) {
 	if v != nil && reflect.TypeOf(v).Kind() == reflect.String {
 		s := reflect.ValueOf(v).String()
 		u, err := url.Parse(s)
 		if err != nil {
-			return nil, error_{"valid url", s, path}
+			return nil, CoerceError{Expected: "valid url", Got: s, Path: path}
+		}
+		if len(c.schemes) > 0 {
+			if err := c.checkScheme(u); err != nil {
+				return nil, errorf(path, "invalid URL %q: %v", s, err)
+			}
+			u.Host = strings.ToLower(u.Host)
 		}
 		return u, nil
 	}
-	return nil, error_{"url string", v, path}
+	return nil, CoerceError{Expected: "url string", Got: v, Path: path}
+}
+
+func (c urlC) checkScheme(u *url.URL) error {
+	if u.Scheme == "" {
+		return fmt.Errorf("missing scheme")
+	}
+	allowed := false
+	for _, scheme := range c.schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("scheme %q not allowed, expected one of %q", u.Scheme, c.schemes)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+type urlStringC struct {
+	urlC
+}
+
+func (c urlStringC) Coerce(v interface{}, path []string) (interface{}, error) {
+	u, err := c.urlC.Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	return u.(*url.URL).String(), nil
 }
 
 // SimpleRegexp returns a checker that accepts a string value that is
@@ -61,36 +119,411 @@ func (c sregexpC) Coerce(v interface{}, path []string) (interface{}, error) {
 		s := reflect.ValueOf(v).String()
 		_, err := regexp.Compile(s)
 		if err != nil {
-			return nil, error_{"valid regexp", s, path}
+			return nil, CoerceError{Expected: "valid regexp", Got: s, Path: path}
 		}
 		return v, nil
 	}
-	return nil, error_{"regexp string", v, path}
+	return nil, CoerceError{Expected: "regexp string", Got: v, Path: path}
 }
 
-// UUID returns a Checker that accepts a string value only and returns
-// it unprocessed.
-func UUID() Checker {
-	return uuidC{}
+// RegexpPattern returns a Checker that accepts a string value that is
+// a valid regular expression and returns it compiled as a *regexp.Regexp.
+// Unlike SimpleRegexp, compilation errors are reported in full, so the
+// offending part of the pattern is included in the error message.
+func RegexpPattern() Checker {
+	return regexpPatternC{}
+}
+
+type regexpPatternC struct{}
+
+func (c regexpPatternC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "regexp string", Got: v, Path: path}
+	}
+	re, err := regexp.Compile(reflect.ValueOf(v).String())
+	if err != nil {
+		return nil, parseError(path, "regexp", err)
+	}
+	return re, nil
+}
+
+// Match returns a Checker that accepts a string value matching the
+// regular expression pattern, and returns it unchanged. The pattern is
+// compiled once, and Match panics if it is invalid.
+func Match(pattern string) Checker {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("Match got an invalid pattern: %v", err))
+	}
+	return matchC{re}
+}
+
+// MatchRegexp returns a Checker that acts as the one returned by Match,
+// using an already compiled regular expression.
+func MatchRegexp(re *regexp.Regexp) Checker {
+	return matchC{re}
+}
+
+type matchC struct {
+	re *regexp.Regexp
+}
+
+func (c matchC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	if !c.re.MatchString(s) {
+		return nil, errorf(path, "value %q does not match pattern %q", s, c.re)
+	}
+	return s, nil
+}
+
+// Filter returns a Checker that accepts a string value matching at
+// least one of the include patterns and none of the exclude patterns,
+// and returns it unchanged, as for allow and deny lists of metric
+// names. Patterns use the syntax of path.Match, as in "node_*". If no
+// include patterns are given, all values not excluded are accepted.
+// Filter panics if any pattern is invalid.
+func Filter(include []string, exclude []string) Checker {
+	checkPatterns("Filter", include)
+	checkPatterns("Filter", exclude)
+	return filterC{include, exclude}
+}
+
+// checkPatterns panics if any of patterns is not a valid path.Match
+// pattern, naming the function that was given them.
+func checkPatterns(caller string, patterns []string) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("%s got an invalid pattern %q: %v", caller, pattern, err))
+		}
+	}
+}
+
+type filterC struct {
+	include []string
+	exclude []string
+}
+
+func (c filterC) Coerce(v interface{}, p []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: p}
+	}
+	s := reflect.ValueOf(v).String()
+	included := len(c.include) == 0
+	for _, pattern := range c.include {
+		if ok, _ := path.Match(pattern, s); ok {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return nil, errorf(p, "value %q does not match any of the include patterns %q", s, c.include)
+	}
+	for _, pattern := range c.exclude {
+		if ok, _ := path.Match(pattern, s); ok {
+			return nil, errorf(p, "value %q is excluded by pattern %q", s, pattern)
+		}
+	}
+	return s, nil
 }
 
-type uuidC struct{}
+// Glob returns a Checker that accepts a string value matching at least
+// one of the given shell patterns, and returns it unchanged, as for
+// filters of resource names. Patterns use the syntax of path.Match, as
+// in "web-*", so "*" doesn't match "/". The error lists the patterns.
+// Glob panics if no patterns are given or any pattern is invalid.
+func Glob(patterns ...string) Checker {
+	if len(patterns) == 0 {
+		panic("Glob got no patterns")
+	}
+	checkPatterns("Glob", patterns)
+	return globC{patterns}
+}
 
-var uuidregex = regexp.MustCompile(`[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}`)
+type globC struct {
+	patterns []string
+}
+
+func (c globC) Coerce(v interface{}, p []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: p}
+	}
+	s := reflect.ValueOf(v).String()
+	for _, pattern := range c.patterns {
+		if ok, _ := path.Match(pattern, s); ok {
+			return s, nil
+		}
+	}
+	return nil, errorf(p, "value %q does not match any of the patterns %q", s, c.patterns)
+}
+
+// Enum returns a Checker that accepts a string value that is one of
+// the given values, and returns it unchanged. Unlike a OneOf of Const
+// checkers, the error lists the allowed values.
+func Enum(values ...string) Checker {
+	return enumC{values, false}
+}
+
+// EnumFold returns a Checker that acts as the one returned by Enum, but
+// matches values ignoring case, and returns the matching value with
+// the spelling it was given to EnumFold, so that EnumFold("TCP", "UDP")
+// turns "tcp" into "TCP".
+func EnumFold(values ...string) Checker {
+	return enumC{values, true}
+}
+
+type enumC struct {
+	values []string
+	fold   bool
+}
+
+func (c enumC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	for _, value := range c.values {
+		if s == value || c.fold && strings.EqualFold(s, value) {
+			return value, nil
+		}
+	}
+	return nil, errorf(path, "%q is not one of %q", s, c.values)
+}
+
+// StringLen returns a Checker that accepts a string value whose length
+// in runes is between min and max, inclusive, and returns it unchanged.
+// A negative max leaves the length unbounded above.
+func StringLen(min, max int) Checker {
+	return stringLenC{min, max}
+}
+
+type stringLenC struct {
+	min, max int
+}
+
+func (c stringLenC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	n := utf8.RuneCountInString(s)
+	if c.max < 0 && n < c.min {
+		return nil, errorf(path, "string length %d is less than the minimum %d", n, c.min)
+	}
+	if c.max >= 0 && (n < c.min || n > c.max) {
+		return nil, errorf(path, "string length %d not in range [%d, %d]", n, c.min, c.max)
+	}
+	return s, nil
+}
+
+// Selector returns a Checker that accepts a string holding an
+// expression in some third-party syntax, such as a CSS selector or an
+// XPath expression, and returns it unprocessed. The expression is
+// validated by calling parse, and any error it returns is reported
+// along with the path of the value.
+func Selector(parse func(string) error) Checker {
+	return selectorC{parse}
+}
+
+type selectorC struct {
+	parse func(string) error
+}
+
+func (c selectorC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	if err := c.parse(s); err != nil {
+		return nil, errorf(path, "invalid selector %q: %v", s, err)
+	}
+	return s, nil
+}
+
+// TemplateResolvable returns a Checker that accepts a string holding a
+// text/template template, and returns it unprocessed once it has been
+// executed successfully against vars. Unlike a syntax check, this
+// catches references to variables missing from vars, as templates are
+// executed with the "missingkey=error" option, so that vars should hold
+// a representative sample of the variables available to the template.
+func TemplateResolvable(vars map[string]interface{}) Checker {
+	return templateResolvableC{vars}
+}
+
+type templateResolvableC struct {
+	vars map[string]interface{}
+}
+
+func (c templateResolvableC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	t, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return nil, errorf(path, "invalid template: %v", err)
+	}
+	if err := t.Execute(io.Discard, c.vars); err != nil {
+		return nil, errorf(path, "template does not resolve: %v", err)
+	}
+	return s, nil
+}
+
+// TagOptions returns a Checker that accepts a comma-separated list of
+// options in the style of Go struct tags, such as "omitempty,required",
+// and returns the options as a []string. Every option must be one of
+// known. Surrounding whitespace is ignored, and options specified more
+// than once are only included the first time they appear. An empty
+// string results in an empty list.
+func TagOptions(known ...string) Checker {
+	c := tagOptionsC{known, make(map[string]bool, len(known))}
+	for _, opt := range known {
+		c.isKnown[opt] = true
+	}
+	return c
+}
+
+type tagOptionsC struct {
+	known   []string
+	isKnown map[string]bool
+}
+
+func (c tagOptionsC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	out := []string{}
+	if strings.TrimSpace(s) == "" {
+		return out, nil
+	}
+	seen := make(map[string]bool)
+	for _, opt := range strings.Split(s, ",") {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			return nil, errorf(path, "empty option in %q", s)
+		}
+		if !c.isKnown[opt] {
+			return nil, errorf(path, "unknown option %q, expected one of %q", opt, c.known)
+		}
+		if !seen[opt] {
+			seen[opt] = true
+			out = append(out, opt)
+		}
+	}
+	return out, nil
+}
+
+// EnvVarName returns a Checker that accepts a string holding a name
+// that can be used for an environment variable in a POSIX shell, made
+// of letters, digits and underscores and not starting with a digit, and
+// returns it unprocessed. The error message quotes the first character
+// that is not allowed.
+func EnvVarName() Checker {
+	return envVarNameC{}
+}
+
+type envVarNameC struct{}
+
+func (c envVarNameC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	if s == "" {
+		return nil, errorf(path, "empty environment variable name")
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return nil, errorf(path, "invalid environment variable name %q: unexpected character %q", s, r)
+		}
+	}
+	return s, nil
+}
+
+// UUIDOption holds an option for UUID.
+type UUIDOption int
+
+// AllowBraces makes UUID accept UUIDs enclosed in braces, as in
+// "{6216dfc3-6e82-408f-9f74-8565e63e6158}", and strip them.
+const AllowBraces UUIDOption = 1
+
+// UUID returns a Checker that accepts a string holding an RFC 4122
+// UUID in its 8-4-4-4-12 hexadecimal form, in any case, and returns it
+// in lower case.
+func UUID(options ...UUIDOption) Checker {
+	var c uuidC
+	for _, opt := range options {
+		if opt == AllowBraces {
+			c.allowBraces = true
+		}
+	}
+	return c
+}
+
+type uuidC struct {
+	allowBraces bool
+}
+
+var uuidregex = regexp.MustCompile(`^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$`)
 
 func (c uuidC) Coerce(v interface{}, path []string) (interface{}, error) {
-	if v != nil && reflect.TypeOf(v).Kind() == reflect.String {
-		uuid := reflect.ValueOf(v).String()
-		if uuidregex.MatchString(uuid) {
-			return uuid, nil
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "uuid", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	uuid := strings.ToLower(s)
+	if c.allowBraces && strings.HasPrefix(uuid, "{") && strings.HasSuffix(uuid, "}") {
+		uuid = uuid[1 : len(uuid)-1]
+	}
+	if !uuidregex.MatchString(uuid) {
+		return nil, errorf(path, "%q is not a valid UUID", s)
+	}
+	return uuid, nil
+}
+
+// ULID returns a Checker that accepts a string holding a ULID, made of
+// 26 characters in Crockford's base32 alphabet, and returns it in the
+// canonical upper case form. Lower case letters are accepted, but not
+// the letters I, L, O and U, which the alphabet excludes. As a ULID
+// holds 128 bits, its first character can be no greater than 7.
+func ULID() Checker {
+	return ulidC{}
+}
+
+type ulidC struct{}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func (c ulidC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	s := reflect.ValueOf(v).String()
+	if len(s) != 26 {
+		return nil, errorf(path, "invalid ULID %q: expected 26 characters, got %d", s, len(s))
+	}
+	id := strings.ToUpper(s)
+	for _, r := range id {
+		if !strings.ContainsRune(crockfordAlphabet, r) {
+			return nil, errorf(path, "invalid ULID %q: character %q is not in the Crockford base32 alphabet", s, r)
 		}
 	}
-	return nil, error_{"uuid", v, path}
+	if id[0] > '7' {
+		return nil, errorf(path, "invalid ULID %q: first character must be between 0 and 7", s)
+	}
+	return id, nil
 }
 
 // Stringified returns a checker that accepts a bool/int/float/string
 // value and returns its string. Other value types may be supported by
-// passing in their checkers.
+// passing in their checkers, which only widen the accepted types; to
+// validate the resulting string, use StringifiedThen.
 func Stringified(checkers ...Checker) Checker {
 	return stringifiedC{
 		checkers: checkers,
@@ -118,6 +551,35 @@ func (c stringifiedC) Coerce(v interface{}, path []string) (interface{}, error)
 	return fmt.Sprintf("%#v", v), nil
 }
 
+// StringifiedThen returns a checker that accepts a bool, integer, float
+// or string value, converts it to a string, with numbers in decimal and
+// floats rendered without trailing zeros, and returns the result of
+// coercing that string with inner. Other values, such as maps and lists,
+// are rejected.
+func StringifiedThen(inner Checker) Checker {
+	return stringifiedThenC{inner}
+}
+
+type stringifiedThenC struct {
+	inner Checker
+}
+
+func (c stringifiedThenC) Coerce(v interface{}, path []string) (interface{}, error) {
+	var s string
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.String:
+		s = rv.String()
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		s = fmt.Sprint(v)
+	default:
+		return nil, CoerceError{Expected: "bool, number or string", Got: v, Path: path}
+	}
+	return c.inner.Coerce(s, path)
+}
+
 // NonEmptyString returns a Checker that only accepts non-empty strings. To
 // tweak the error message, valueLabel can contain a label of the value being
 // checked, e.g. "my special name". If valueLabel is "", "string" will be used
@@ -134,22 +596,56 @@ func NonEmptyString(valueLabel string) Checker {
 	if valueLabel == "" {
 		valueLabel = "string"
 	}
-	return nonEmptyStringC{valueLabel}
+	return nonEmptyStringC{valueLabel: valueLabel}
+}
+
+// NonBlankString returns a Checker that acts as the one returned by
+// NonEmptyString, but also rejects strings made only of white space.
+// The string is returned as is, including any surrounding white space.
+// An empty or blank string is reported with an error like
+// `empty name not valid`.
+func NonBlankString(valueLabel string) Checker {
+	c := NonEmptyString(valueLabel).(nonEmptyStringC)
+	c.blank = true
+	return c
+}
+
+// TrimmedNonEmptyString returns a Checker that acts as the one returned
+// by NonBlankString, but returns the string with surrounding white
+// space removed.
+func TrimmedNonEmptyString(valueLabel string) Checker {
+	c := NonEmptyString(valueLabel).(nonEmptyStringC)
+	c.blank = true
+	c.trim = true
+	return c
 }
 
 type nonEmptyStringC struct {
 	valueLabel string
+	blank      bool
+	trim       bool
 }
 
 func (c nonEmptyStringC) Coerce(v interface{}, path []string) (interface{}, error) {
 	label := fmt.Sprintf("non-empty %s", c.valueLabel)
-	invalidError := error_{label, v, path}
+	invalidError := CoerceError{Expected: label, Got: v, Path: path}
 
 	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
 		return nil, invalidError
 	}
-	if stringValue := reflect.ValueOf(v).String(); stringValue != "" {
-		return stringValue, nil
+	stringValue := reflect.ValueOf(v).String()
+	trimmed := stringValue
+	if c.blank {
+		trimmed = strings.TrimSpace(stringValue)
+	}
+	if trimmed == "" {
+		if c.blank {
+			return nil, errorf(path, "empty %s not valid", c.valueLabel)
+		}
+		return nil, invalidError
+	}
+	if c.trim {
+		return trimmed, nil
 	}
-	return nil, invalidError
+	return stringValue, nil
 }
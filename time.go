@@ -4,22 +4,52 @@
 package schema
 
 import (
+	"fmt"
+	"math"
 	"reflect"
+	"strings"
 	"time"
 )
 
+// TimeOption holds an option for Time and TimeFormat.
+type TimeOption int
+
+// AllowUnix makes Time and TimeFormat also accept an integer holding a
+// Unix timestamp in seconds, returned as a time in UTC.
+const AllowUnix TimeOption = 1
+
 // Time returns a Checker that accepts a string value, and returns
 // the parsed time.Time value. Emtpy strings are considered empty times.
-func Time() Checker {
-	return timeC{}
+func Time(options ...TimeOption) Checker {
+	return TimeFormat(time.RFC3339Nano, options...)
 }
 
-type timeC struct{}
+// TimeFormat returns a Checker that acts as the one returned by Time,
+// but parses strings with the given time.Parse layout rather than as
+// RFC 3339 times.
+func TimeFormat(layout string, options ...TimeOption) Checker {
+	c := timeC{layout: layout}
+	for _, opt := range options {
+		if opt == AllowUnix {
+			c.allowUnix = true
+		}
+	}
+	return c
+}
+
+type timeC struct {
+	layout    string
+	allowUnix bool
+}
 
 // Coerce implements Checker Coerce method.
 func (c timeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	want := "string or time.Time"
+	if c.allowUnix {
+		want = "string, time.Time or Unix timestamp"
+	}
 	if v == nil {
-		return nil, error_{"string or time.Time", v, path}
+		return nil, CoerceError{Expected: want, Got: v, Path: path}
 	}
 	var empty time.Time
 	switch reflect.TypeOf(v).Kind() {
@@ -30,12 +60,98 @@ func (c timeC) Coerce(v interface{}, path []string) (interface{}, error) {
 		if vstr == "" {
 			return empty, nil
 		}
-		v, err := time.Parse(time.RFC3339Nano, vstr)
+		v, err := time.Parse(c.layout, vstr)
 		if err != nil {
 			return nil, parseError(path, "time", err)
 		}
 		return v, nil
-	default:
-		return nil, error_{"string or time.Time", v, path}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if c.allowUnix {
+			return time.Unix(reflect.ValueOf(v).Int(), 0).UTC(), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if c.allowUnix && reflect.ValueOf(v).Uint() <= math.MaxInt64 {
+			return time.Unix(int64(reflect.ValueOf(v).Uint()), 0).UTC(), nil
+		}
+	}
+	return nil, CoerceError{Expected: want, Got: v, Path: path}
+}
+
+// TimeOfDay returns a Checker that accepts a string holding a time of
+// day in the "15:04" or "15:04:05" format, and returns the time elapsed
+// since midnight as a time.Duration. Impossible times such as "25:00"
+// are rejected.
+func TimeOfDay() Checker {
+	return timeOfDayC{}
+}
+
+type timeOfDayC struct{}
+
+// Coerce implements Checker Coerce method.
+func (c timeOfDayC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.String {
+		return nil, CoerceError{Expected: "string", Got: v, Path: path}
+	}
+	d, err := parseTimeOfDay(reflect.ValueOf(v).String())
+	if err != nil {
+		return nil, parseError(path, "time of day", err)
+	}
+	return d, nil
+}
+
+// TimeOfDayRange returns a Checker that acts as the one returned by
+// TimeOfDay, but additionally requires the time to fall between from
+// and to, inclusive. If from is later than to, the range wraps around
+// midnight, so that TimeOfDayRange("22:00", "06:00") accepts "23:30" and
+// "05:00" but not "12:00". TimeOfDayRange panics if either bound is not
+// a valid time of day.
+func TimeOfDayRange(from, to string) Checker {
+	fromd, err := parseTimeOfDay(from)
+	if err != nil {
+		panic(fmt.Sprintf("TimeOfDayRange got an invalid start time: %v", err))
+	}
+	tod, err := parseTimeOfDay(to)
+	if err != nil {
+		panic(fmt.Sprintf("TimeOfDayRange got an invalid end time: %v", err))
+	}
+	return timeOfDayRangeC{from, to, fromd, tod}
+}
+
+type timeOfDayRangeC struct {
+	fromLabel, toLabel string
+	from, to           time.Duration
+}
+
+// Coerce implements Checker Coerce method.
+func (c timeOfDayRangeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	newv, err := timeOfDayC{}.Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	d := newv.(time.Duration)
+	var ok bool
+	if c.from <= c.to {
+		ok = d >= c.from && d <= c.to
+	} else {
+		ok = d >= c.from || d <= c.to
+	}
+	if !ok {
+		want := fmt.Sprintf("time of day between %s and %s", c.fromLabel, c.toLabel)
+		return nil, CoerceError{Expected: want, Got: v, Path: path}
+	}
+	return d, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	layout := "15:04:05"
+	if strings.Count(s, ":") == 1 {
+		layout = "15:04"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, err
 	}
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second, nil
 }
@@ -4,7 +4,9 @@
 package schema
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"time"
 )
 
@@ -45,9 +47,74 @@ func (c timeDurationStringC) Coerce(v interface{}, path []string) (interface{},
 	return d.String(), nil
 }
 
+// DurationMultipleOf returns a Checker that acts as the one returned by
+// TimeDuration, but additionally requires the duration to be an exact
+// multiple of base, as for intervals that must be whole seconds.
+// DurationMultipleOf panics if base is not positive.
+func DurationMultipleOf(base time.Duration) Checker {
+	if base <= 0 {
+		panic("DurationMultipleOf got a non-positive base")
+	}
+	return durationMultipleOfC{base}
+}
+
+type durationMultipleOfC struct {
+	base time.Duration
+}
+
+// Coerce implements Checker Coerce method.
+func (c durationMultipleOfC) Coerce(v interface{}, path []string) (interface{}, error) {
+	dur, err := asTimeDuration(v, path)
+	if err != nil {
+		return nil, err
+	}
+	d := time.Duration(reflect.ValueOf(dur).Int())
+	if d%c.base != 0 {
+		return nil, errorf(path, "expected a multiple of %v, got %v", c.base, d)
+	}
+	return d, nil
+}
+
+// DurationEnum returns a Checker that acts as the one returned by
+// TimeDuration, but additionally requires the duration to be one of
+// allowed, such as "1m", "5m" and "1h". Durations are compared once
+// parsed, so that "60s" matches "1m". DurationEnum panics if any of
+// allowed is not a valid duration.
+func DurationEnum(allowed ...string) Checker {
+	c := durationEnumC{allowed: allowed}
+	for _, s := range allowed {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			panic(fmt.Sprintf("DurationEnum got an invalid duration: %v", err))
+		}
+		c.durations = append(c.durations, d)
+	}
+	return c
+}
+
+type durationEnumC struct {
+	allowed   []string
+	durations []time.Duration
+}
+
+// Coerce implements Checker Coerce method.
+func (c durationEnumC) Coerce(v interface{}, path []string) (interface{}, error) {
+	dur, err := asTimeDuration(v, path)
+	if err != nil {
+		return nil, err
+	}
+	d := time.Duration(reflect.ValueOf(dur).Int())
+	for _, allowed := range c.durations {
+		if d == allowed {
+			return d, nil
+		}
+	}
+	return nil, errorf(path, "expected one of %s, got %v", strings.Join(c.allowed, ", "), v)
+}
+
 func asTimeDuration(v interface{}, path []string) (interface{}, error) {
 	if v == nil {
-		return nil, error_{want: "string or time.Duration", got: v, path: path}
+		return nil, CoerceError{Expected: "string or time.Duration", Got: v, Path: path}
 	}
 
 	var empty time.Duration
@@ -65,6 +132,6 @@ func asTimeDuration(v interface{}, path []string) (interface{}, error) {
 		}
 		return v, nil
 	default:
-		return nil, error_{"string or time.Duration", v, path}
+		return nil, CoerceError{Expected: "string or time.Duration", Got: v, Path: path}
 	}
 }
@@ -78,3 +78,51 @@ func (s *timeDurationSuite) TestTimeDurationString(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, "<path>: expected string or time.Duration, got nothing")
 	c.Check(out, gc.Equals, "")
 }
+
+func (s *timeDurationSuite) TestDurationMultipleOf(c *gc.C) {
+	sch := schema.DurationMultipleOf(time.Second)
+
+	out, err := sch.Coerce("1m30s", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, 90*time.Second)
+
+	out, err = sch.Coerce(2*time.Second, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, 2*time.Second)
+
+	out, err = sch.Coerce("", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, time.Duration(0))
+
+	_, err = sch.Coerce("1.5s", aPath)
+	c.Check(err.Error(), gc.Equals, "<path>: expected a multiple of 1s, got 1.5s")
+
+	_, err = sch.Coerce("1x", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: conversion to duration: .*`)
+
+	c.Check(func() { schema.DurationMultipleOf(0) }, gc.PanicMatches, "DurationMultipleOf got a non-positive base")
+}
+
+func (s *timeDurationSuite) TestDurationEnum(c *gc.C) {
+	sch := schema.DurationEnum("1m", "5m", "15m", "1h")
+
+	out, err := sch.Coerce("5m", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, 5*time.Minute)
+
+	out, err = sch.Coerce("60s", aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, time.Minute)
+
+	out, err = sch.Coerce(time.Hour, aPath)
+	c.Assert(err, gc.IsNil)
+	c.Check(out, gc.Equals, time.Hour)
+
+	_, err = sch.Coerce("2m", aPath)
+	c.Check(err.Error(), gc.Equals, "<path>: expected one of 1m, 5m, 15m, 1h, got 2m")
+
+	_, err = sch.Coerce("soon", aPath)
+	c.Check(err, gc.ErrorMatches, `<path>: conversion to duration: .*`)
+
+	c.Check(func() { schema.DurationEnum("1m", "often") }, gc.PanicMatches, `DurationEnum got an invalid duration: .*`)
+}